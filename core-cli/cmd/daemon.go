@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"core/daemon"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+func getDaemonRoot() string {
+	if len(env.TASK_ROOT) > 0 {
+		return env.TASK_ROOT
+	}
+	return env.WIKI_ROOT
+}
+
+// queryDaemon returns false when the daemon is disabled, can't be reached or serves another root,
+// other errors reported by the daemon are printed and exit instead of falling back to a local run
+func queryDaemon(cmd *cobra.Command, request daemon.Request) (*daemon.Response, bool) {
+	if noDaemon, _ := cmd.Flags().GetBool("no-daemon"); noDaemon {
+		return nil, false
+	}
+	response, err := daemon.Query(daemon.GetSocketPath(env.CORE_SOCKET), request)
+	if response != nil && response.Error != "" {
+		fmt.Fprintln(os.Stderr, response.Error)
+		os.Exit(1)
+	}
+	if err != nil {
+		return nil, false
+	}
+	return response, true
+}
+
+var daemonCommand = &cobra.Command{
+	Use:   "daemon",
+	Short: "keep the wiki loaded and answer task queries over a unix socket",
+	Run: func(cmd *cobra.Command, args []string) {
+		root, err := cmd.Flags().GetString("root")
+		if err != nil {
+			panic(err)
+		}
+		if len(root) == 0 {
+			root = getDaemonRoot()
+		}
+		if len(root) == 0 {
+			panic("TASK_ROOT or WIKI_ROOT not set")
+		}
+
+		socketPath := daemon.GetSocketPath(env.CORE_SOCKET)
+		server, err := daemon.NewServer(daemon.ServerConfig{
+			Root:       root,
+			SocketPath: socketPath,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-signals
+			server.Close()
+		}()
+
+		fmt.Fprintf(os.Stderr, "serving %s on %s\n", root, socketPath)
+		if err := server.Serve(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Remove(socketPath)
+	},
+}
+
+func init() {
+	daemonCommand.Flags().String("root", "", "wiki root to serve (default: TASK_ROOT, then WIKI_ROOT)")
+	rootCmd.AddCommand(daemonCommand)
+
+	rootCmd.PersistentFlags().Bool("no-daemon", false, "never query a running daemon")
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"core/server"
+	"core/utils"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	local_wiki "github.com/3rd/core/core-lib/wiki/local"
+	"github.com/radovskyb/watcher"
+	"github.com/spf13/cobra"
+)
+
+var serveCommand = &cobra.Command{
+	Use:   "serve",
+	Short: "serve the wiki and tasks over a local HTTP/JSON API",
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.WIKI_ROOT
+		if len(root) == 0 {
+			panic("WIKI_ROOT not set")
+		}
+
+		addr, err := cmd.Flags().GetString("addr")
+		if err != nil {
+			panic(err)
+		}
+
+		wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
+			Root:  root,
+			Parse: "meta",
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		apiServer := server.NewServer(wikiInstance, addr)
+
+		w, err := utils.NewRootWatcher(root, func(event watcher.Event) {
+			err := apiServer.Reload(server.ChangeEvent{Op: event.Op.String(), Path: event.Path})
+			if err != nil {
+				log.Println(err)
+			}
+		})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer w.Close()
+		go w.Start(time.Millisecond * 100)
+
+		fmt.Fprintf(os.Stderr, "listening on http://%s\n", addr)
+		log.Fatalln(http.ListenAndServe(addr, apiServer.Handler()))
+	},
+}
+
+func init() {
+	serveCommand.Flags().String("addr", "127.0.0.1:7777", "listen address")
+	rootCmd.AddCommand(serveCommand)
+}
@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"core/daemon"
 	taskinteractive "core/ui/task_interactive"
+	"core/utils"
 	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/3rd/core/core-lib/wiki"
@@ -45,6 +50,20 @@ func formatRepeatDayStr(input string) string {
 	return input
 }
 
+// exit code used by `task current` when nothing is in progress
+const TASK_CURRENT_EXIT_CODE_IDLE = 1
+
+func loadTaskWiki(root string) wiki.Wiki {
+	wikiInstance, err := localWiki.NewLocalWiki(localWiki.LocalWikiConfig{
+		Root:  root,
+		Parse: "meta",
+	})
+	if err != nil {
+		panic(err)
+	}
+	return wikiInstance
+}
+
 var taskCurrentCommand = &cobra.Command{
 	Use:   "current",
 	Short: "list the currently in-progress task (first only)",
@@ -54,39 +73,71 @@ var taskCurrentCommand = &cobra.Command{
 			panic("WIKI_ROOT not set")
 		}
 
-		wiki, err := localWiki.NewLocalWiki(localWiki.LocalWikiConfig{
-			Root:  root,
-			Parse: "full",
-		})
-		if err != nil {
-			panic(err)
+		showElapsed, _ := cmd.Flags().GetBool("elapsed")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		format, _ := cmd.Flags().GetString("format")
+
+		var tpl *template.Template
+		if format != "" {
+			var err error
+			tpl, err = template.New("current").Parse(format)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
 		}
 
-		nodes, err := wiki.GetNodes()
-		if err != nil {
-			panic(err)
+		var current *utils.CurrentTask
+		if response, ok := queryDaemon(cmd, daemon.Request{Command: daemon.COMMAND_CURRENT, Root: root}); ok {
+			current = response.Current
+		} else {
+			nodes, err := loadTaskWiki(root).GetNodes()
+			if err != nil {
+				panic(err)
+			}
+			task := utils.FindCurrentTask(nodes)
+			if task != nil {
+				result := utils.NewCurrentTask(task, time.Now())
+				current = &result
+			}
+		}
+
+		if current == nil {
+			os.Exit(TASK_CURRENT_EXIT_CODE_IDLE)
 		}
 
-		for _, node := range nodes {
-			meta := node.GetMeta()
-			nodeType, ok := meta["type"]
-			if !ok || (nodeType != "project" && nodeType != "person") {
-				continue
+		elapsed := time.Duration(current.Elapsed) * time.Second
+
+		if asJSON {
+			data, err := json.MarshalIndent(current, "", "  ")
+			if err != nil {
+				panic(err)
 			}
+			fmt.Println(string(data))
+			return
+		}
 
-			tasks := node.GetTasks()
-			for _, task := range tasks {
-				if task.IsInProgress() {
-					if cmd.Flag("elapsed").Value != nil {
-						start := task.GetLastSession().Start
-						elapsed := time.Since(start).Round(time.Second)
-						fmt.Printf("%s - %s (%s)\n", node.GetName(), task.Text, elapsed)
-					} else {
-						fmt.Printf("%s - %s\n", node.GetName(), task.Text)
-					}
-					return
-				}
+		if tpl != nil {
+			data := map[string]any{
+				"node":         current.Node.Name,
+				"task":         current.Task.Text,
+				"elapsed":      elapsed,
+				"sessionStart": current.SessionStart,
+				"todayTotal":   time.Duration(current.TodayTotal) * time.Second,
+				"priority":     current.Task.Priority,
+			}
+			if err := tpl.Execute(os.Stdout, data); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
 			}
+			fmt.Println()
+			return
+		}
+
+		if showElapsed {
+			fmt.Printf("%s - %s (%s)\n", current.Node.Name, current.Task.Text, elapsed)
+		} else {
+			fmt.Printf("%s - %s\n", current.Node.Name, current.Task.Text)
 		}
 	},
 }
@@ -97,111 +148,158 @@ var taskActiveCommand = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		includeDone, _ := cmd.Flags().GetBool("include-done")
 		asJSON, _ := cmd.Flags().GetBool("json")
+		asJSONL, _ := cmd.Flags().GetBool("jsonl")
 
 		root := env.TASK_ROOT
 		if len(root) == 0 {
 			panic("TASK_ROOT not set")
 		}
 
-		wikiInstance, err := localWiki.NewLocalWiki(localWiki.LocalWikiConfig{
-			Root:  root,
-			Parse: "full",
-		})
+		var result utils.ActiveTasks
+		if response, ok := queryDaemon(cmd, daemon.Request{Command: daemon.COMMAND_ACTIVE, Root: root, IncludeDone: includeDone}); ok && response.Active != nil {
+			result = *response.Active
+		} else {
+			nodes, err := loadTaskWiki(root).GetNodes()
+			if err != nil {
+				panic(err)
+			}
+			now := time.Now()
+			activeTasks, doneToday := utils.FindActiveTasks(nodes, includeDone, now)
+			result = utils.NewActiveTasks(activeTasks, doneToday, now)
+		}
+
+		if asJSONL {
+			writer := wiki.NewJSONLWriter(os.Stdout)
+			for _, task := range result.ActiveTasks {
+				if err := writer.WriteJSONTask("active", task); err != nil {
+					panic(err)
+				}
+			}
+			for _, task := range result.DoneTasks {
+				if err := writer.WriteJSONTask("done", task); err != nil {
+					panic(err)
+				}
+			}
+			return
+		}
+
+		if asJSON {
+			data, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		for _, task := range result.ActiveTasks {
+			fmt.Printf("%s - %s\n", task.NodeName, task.Text)
+		}
+		for _, task := range result.DoneTasks {
+			fmt.Printf("%s - %s\n", task.NodeName, task.Text)
+		}
+	},
+}
+
+var taskStartCommand = &cobra.Command{
+	Use:   "start <node> <line>",
+	Short: "start a work session on the task at the given line",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.TASK_ROOT
+		if len(root) == 0 {
+			panic("TASK_ROOT not set")
+		}
+
+		lineNumber, err := strconv.ParseUint(args[1], 10, 32)
 		if err != nil {
 			panic(err)
 		}
 
-		nodes, err := wikiInstance.GetNodes()
+		request := daemon.Request{Command: daemon.COMMAND_START, Root: root, NodeID: args[0], LineNumber: uint32(lineNumber)}
+		if _, ok := queryDaemon(cmd, request); ok {
+			return
+		}
+
+		wikiInstance := loadTaskWiki(root)
+		node, err := wikiInstance.GetNode(args[0])
 		if err != nil {
 			panic(err)
 		}
-
-		// active only
-		if !includeDone && !asJSON {
-			for _, node := range nodes {
-				meta := node.GetMeta()
-				nodeType, ok := meta["type"]
-				if !ok || (nodeType != "project" && nodeType != "person") {
-					continue
+		if node == nil {
+			fmt.Fprintf(os.Stderr, "node not found: %s\n", args[0])
+			os.Exit(1)
+		}
+		for _, task := range node.GetTasks() {
+			if task.LineNumber == uint32(lineNumber) {
+				if task.IsInProgress() {
+					fmt.Fprintln(os.Stderr, "task already in progress")
+					os.Exit(1)
 				}
-
-				nodeTasks := node.GetTasks()
-				for _, task := range nodeTasks {
-					if task.Status == wiki.TASK_STATUS_ACTIVE {
-						fmt.Printf("%s - %s\n", node.GetName(), task.Text)
-					}
+				if err := wikiInstance.StartTaskSession(task, time.Now()); err != nil {
+					panic(err)
 				}
+				return
 			}
+		}
+		fmt.Fprintf(os.Stderr, "no task at %s:%d\n", args[0], lineNumber)
+		os.Exit(1)
+	},
+}
+
+var taskStopCommand = &cobra.Command{
+	Use:   "stop",
+	Short: "stop the current work session",
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.TASK_ROOT
+		if len(root) == 0 {
+			panic("TASK_ROOT not set")
+		}
+
+		if _, ok := queryDaemon(cmd, daemon.Request{Command: daemon.COMMAND_STOP, Root: root}); ok {
 			return
 		}
 
-		// with flags
-		type simpleTask struct {
-			Node wiki.Node
-			Task *wiki.Task
+		wikiInstance := loadTaskWiki(root)
+		nodes, err := wikiInstance.GetNodes()
+		if err != nil {
+			panic(err)
+		}
+		task := utils.FindOpenSessionTask(nodes)
+		if task == nil {
+			os.Exit(TASK_CURRENT_EXIT_CODE_IDLE)
+		}
+		if err := wikiInstance.StopTaskSession(task, time.Now()); err != nil {
+			panic(err)
 		}
-		activeTasks := []simpleTask{}
-		doneToday := []simpleTask{}
+	},
+}
 
-		now := time.Now()
-		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
-		endOfDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local)
+var taskReportCommand = &cobra.Command{
+	Use:   "report",
+	Short: "show time spent on each task today",
+	Run: func(cmd *cobra.Command, args []string) {
+		asJSON, _ := cmd.Flags().GetBool("json")
 
-		for _, node := range nodes {
-			meta := node.GetMeta()
-			nodeType, ok := meta["type"]
-			if !ok || (nodeType != "project" && nodeType != "person") {
-				continue
-			}
+		root := env.TASK_ROOT
+		if len(root) == 0 {
+			panic("TASK_ROOT not set")
+		}
 
-			nodeTasks := node.GetTasks()
-			for _, task := range nodeTasks {
-				if task.Status == wiki.TASK_STATUS_ACTIVE {
-					activeTasks = append(activeTasks, simpleTask{Node: node, Task: task})
-				}
-				if includeDone && task.Status == wiki.TASK_STATUS_DONE {
-					for _, session := range task.Sessions {
-						if session.Start.After(startOfDay) && session.Start.Before(endOfDay) {
-							doneToday = append(doneToday, simpleTask{Node: node, Task: task})
-							break
-						}
-					}
-				}
+		var report utils.Report
+		if response, ok := queryDaemon(cmd, daemon.Request{Command: daemon.COMMAND_REPORT, Root: root}); ok && response.Report != nil {
+			report = *response.Report
+		} else {
+			nodes, err := loadTaskWiki(root).GetNodes()
+			if err != nil {
+				panic(err)
 			}
+			now := time.Now()
+			report = utils.NewReport(utils.GetReport(nodes, now), now)
 		}
 
 		if asJSON {
-			type JSONTask struct {
-				NodeID   string `json:"nodeId"`
-				NodeName string `json:"nodeName"`
-				Text     string `json:"text"`
-				Status   string `json:"status"`
-			}
-			payload := struct {
-				GeneratedAt string     `json:"generatedAt"`
-				ActiveTasks []JSONTask `json:"activeTasks"`
-				DoneTasks   []JSONTask `json:"doneTasks,omitempty"`
-			}{GeneratedAt: now.Format(time.RFC3339)}
-
-			for _, it := range activeTasks {
-				payload.ActiveTasks = append(payload.ActiveTasks, JSONTask{
-					NodeID:   it.Node.GetID(),
-					NodeName: it.Node.GetName(),
-					Text:     it.Task.Text,
-					Status:   string(it.Task.Status),
-				})
-			}
-			if includeDone {
-				for _, it := range doneToday {
-					payload.DoneTasks = append(payload.DoneTasks, JSONTask{
-						NodeID:   it.Node.GetID(),
-						NodeName: it.Node.GetName(),
-						Text:     it.Task.Text,
-						Status:   string(it.Task.Status),
-					})
-				}
-			}
-			data, err := json.MarshalIndent(payload, "", "  ")
+			data, err := json.MarshalIndent(report, "", "  ")
 			if err != nil {
 				panic(err)
 			}
@@ -209,12 +307,13 @@ var taskActiveCommand = &cobra.Command{
 			return
 		}
 
-		for _, it := range activeTasks {
-			fmt.Printf("%s - %s\n", it.Node.GetName(), it.Task.Text)
-		}
-		for _, it := range doneToday {
-			fmt.Printf("%s - %s\n", it.Node.GetName(), it.Task.Text)
+		total := time.Duration(0)
+		for _, entry := range report.Entries {
+			duration := time.Duration(entry.Duration) * time.Second
+			total += duration
+			fmt.Printf("%s - %s (%s)\n", entry.NodeName, entry.Text, duration)
 		}
+		fmt.Printf("total: %s\n", total)
 	},
 }
 
@@ -229,7 +328,7 @@ var taskInteractiveCommand = &cobra.Command{
 
 		wikiInstance, err := localWiki.NewLocalWiki(localWiki.LocalWikiConfig{
 			Root:            root,
-			Parse:           "full",
+			Parse:           "meta",
 			SkipInitialLoad: true,
 		})
 		if err != nil {
@@ -408,9 +507,7 @@ var taskInteractiveCommand = &cobra.Command{
 				}
 
 				// by location
-				aNode := a.Node.(*localWiki.LocalNode)
-				bNode := b.Node.(*localWiki.LocalNode)
-				if aNode.GetPath() == bNode.GetPath() {
+				if a.Node.GetPath() == b.Node.GetPath() {
 					return a.LineNumber < b.LineNumber
 				}
 				return a.Node.GetName() < b.Node.GetName()
@@ -438,9 +535,14 @@ var taskInteractiveCommand = &cobra.Command{
 			return root
 		}
 
+		getWiki := func() wiki.Wiki {
+			return wikiInstance
+		}
+
 		providers := taskinteractive.Providers{
 			GetTasks: loadTasks,
 			GetRoot:  getRoot,
+			GetWiki:  getWiki,
 		}
 		taskinteractive.Run(providers)
 	},
@@ -450,10 +552,17 @@ func init() {
 	cmd := &cobra.Command{Use: "task"}
 
 	taskCurrentCommand.Flags().BoolP("elapsed", "e", false, "include elapsed time")
+	taskCurrentCommand.Flags().Bool("json", false, "output structured JSON")
+	taskCurrentCommand.Flags().StringP("format", "f", "", "Go template (fields: node, task, elapsed, sessionStart, todayTotal, priority)")
 	taskActiveCommand.Flags().Bool("include-done", false, "include tasks done since today 00:00")
 	taskActiveCommand.Flags().Bool("json", false, "output structured JSON")
+	taskActiveCommand.Flags().Bool("jsonl", false, "output one JSON record per line")
+	taskReportCommand.Flags().Bool("json", false, "output structured JSON")
 	cmd.AddCommand(taskCurrentCommand)
 	cmd.AddCommand(taskActiveCommand)
+	cmd.AddCommand(taskStartCommand)
+	cmd.AddCommand(taskStopCommand)
+	cmd.AddCommand(taskReportCommand)
 	cmd.AddCommand(taskInteractiveCommand)
 
 	rootCmd.AddCommand(cmd)
@@ -1,16 +1,21 @@
 package cmd
 
 import (
+	nodepicker "core/ui/node_picker"
+	"core/utils"
 	wikivfs "core/vfs/wiki-vfs"
 	"fmt"
-	"log"
+	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
-	"slices"
-
+	"bazil.org/fuse"
+	"github.com/3rd/core/core-lib/wiki"
 	local_wiki "github.com/3rd/core/core-lib/wiki/local"
-	"github.com/radovskyb/watcher"
+	"github.com/3rd/core/core-lib/wiki/markdown"
+	"github.com/3rd/core/core-lib/wiki/resolve"
 	"github.com/spf13/cobra"
 )
 
@@ -32,13 +37,15 @@ func parseTypes(typeFilter string) []string {
 	return types
 }
 
-func matchesAnyType(nodeType string, types []string) bool {
+func getTypeFilter(types []string) wiki.NodeFilter {
 	if len(types) == 0 {
-		return true
+		return wiki.And()
 	}
-	return slices.Contains(types, nodeType)
+	return wiki.MetaIn("type", types...)
 }
 
+var wikiCommand = &cobra.Command{Use: "wiki"}
+
 var wikiListCommand = &cobra.Command{
 	Use:   "ls",
 	Short: "list wiki nodes",
@@ -58,7 +65,7 @@ var wikiListCommand = &cobra.Command{
 			panic(err)
 		}
 
-		types := parseTypes(typeFilter)
+		filter := getTypeFilter(parseTypes(typeFilter))
 
 		// regular
 		if !isDebug {
@@ -69,16 +76,9 @@ var wikiListCommand = &cobra.Command{
 			if err != nil {
 				panic(err)
 			}
-			nodes, _ := wiki.GetNodes()
-
+			nodes, _ := wiki.FindNodes(filter)
 			for _, node := range nodes {
-				meta := node.GetMeta()
-				if meta != nil {
-					nodeType := meta["type"]
-					if matchesAnyType(nodeType, types) {
-						fmt.Printf("%s\n", node.GetID())
-					}
-				}
+				fmt.Printf("%s\n", node.GetID())
 			}
 		}
 
@@ -91,14 +91,10 @@ var wikiListCommand = &cobra.Command{
 			if err != nil {
 				panic(err)
 			}
-			nodes, _ := wiki.GetNodes()
+			nodes, _ := wiki.GetLocalNodes()
 			for _, node := range nodes {
-				meta := node.GetMeta()
-				if meta != nil {
-					nodeType := meta["type"]
-					if matchesAnyType(nodeType, types) {
-						fmt.Printf("%s %s\n", node.GetID(), node.ParseDuration)
-					}
+				if filter(node) {
+					fmt.Printf("%s %s\n", node.GetID(), node.ParseDuration)
 				}
 			}
 		}
@@ -109,15 +105,29 @@ var wikiResolveCommand = &cobra.Command{
 	Use:   "resolve <node>",
 	Short: "show node file path",
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) == 0 {
-			panic("No node specified")
+		isStrict, err := cmd.Flags().GetBool("strict")
+		if err != nil {
+			panic(err)
 		}
 
-		isStrict, err := cmd.Flags().GetBool("strict")
+		isInteractive, err := cmd.Flags().GetBool("interactive")
+		if err != nil {
+			panic(err)
+		}
+
+		showAll, err := cmd.Flags().GetBool("all")
 		if err != nil {
 			panic(err)
 		}
 
+		if len(args) == 0 && !isInteractive {
+			panic("No node specified")
+		}
+		target := ""
+		if len(args) > 0 {
+			target = args[0]
+		}
+
 		root := env.WIKI_ROOT
 		if len(root) == 0 {
 			panic("WIKI_ROOT not set")
@@ -131,17 +141,46 @@ var wikiResolveCommand = &cobra.Command{
 			panic(err)
 		}
 
-		target := args[0]
-
 		nodes, err := wiki.GetNodes()
 		if err != nil {
 			panic(err)
 		}
-		for _, node := range nodes {
-			if node.GetName() == target {
-				fmt.Print(node.GetPath())
-				return
+
+		if isInteractive {
+			item := nodepicker.Run(nodepicker.Providers{
+				GetItems: func(query string) []nodepicker.Item {
+					items := []nodepicker.Item{}
+					for _, candidate := range resolve.Rank(nodes, query) {
+						detail := ""
+						if candidate.Field != resolve.FIELD_NAME {
+							detail = fmt.Sprintf("(%s: %s)", candidate.Field, candidate.Match)
+						}
+						items = append(items, nodepicker.Item{
+							Label:  candidate.Node.GetName(),
+							Detail: detail,
+							Value:  candidate.Node.GetPath(),
+						})
+					}
+					return items
+				},
+			}, target)
+			if item == nil {
+				os.Exit(1)
+			}
+			fmt.Print(item.Value)
+			return
+		}
+
+		if showAll {
+			for _, candidate := range resolve.Rank(nodes, target) {
+				fmt.Println(candidate.Node.GetPath())
 			}
+			return
+		}
+
+		if node, ok := resolve.Exact(nodes, target); ok {
+			fmt.Print(node.GetPath())
+			return
 		}
 
 		if !isStrict {
@@ -157,7 +196,8 @@ var wikiMountCommand = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		root := env.WIKI_ROOT
 		if len(root) == 0 {
-			panic("WIKI_ROOT not set")
+			fmt.Fprintln(os.Stderr, "WIKI_ROOT not set")
+			os.Exit(1)
 		}
 
 		mountPoint, err := cmd.Flags().GetString("mount")
@@ -165,60 +205,173 @@ var wikiMountCommand = &cobra.Command{
 			panic(err)
 		}
 
-		// get wiki
-		wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
-			Root:  root,
-			Parse: "none",
-		})
+		isWritable, err := cmd.Flags().GetBool("writable")
 		if err != nil {
 			panic(err)
 		}
 
-		// setup watcher
-		w := watcher.New()
-		w.FilterOps(watcher.Create, watcher.Move, watcher.Remove, watcher.Write)
+		cacheSize, err := cmd.Flags().GetInt("cache-size")
+		if err != nil {
+			panic(err)
+		}
 
-		go func() {
-			for {
-				select {
-				case <-w.Event:
-					wikiInstance.Reload()
-				case err := <-w.Error:
-					log.Fatalln(err)
-				case <-w.Closed:
-					return
-				}
+		isDaemon, err := cmd.Flags().GetBool("daemon")
+		if err != nil {
+			panic(err)
+		}
+
+		pidfilePath, err := cmd.Flags().GetString("pidfile")
+		if err != nil {
+			panic(err)
+		}
+		if pidfilePath == "" {
+			pidfilePath = getMountPidfilePath(mountPoint)
+		}
+
+		logPath, err := cmd.Flags().GetString("log")
+		if err != nil {
+			panic(err)
+		}
+
+		viewsFlag, err := cmd.Flags().GetString("views")
+		if err != nil {
+			panic(err)
+		}
+		views, err := wikivfs.ParseViews(viewsFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		hasVirtualDirs, err := cmd.Flags().GetBool("virtual-dirs")
+		if err != nil {
+			panic(err)
+		}
+
+		hasFrontmatter, err := cmd.Flags().GetBool("frontmatter")
+		if err != nil {
+			panic(err)
+		}
+
+		shouldRewriteLinks, err := cmd.Flags().GetBool("rewrite-links")
+		if err != nil {
+			panic(err)
+		}
+
+		checkboxStyle, err := cmd.Flags().GetString("checkboxes")
+		if err != nil {
+			panic(err)
+		}
+		if checkboxStyle != markdown.CHECKBOX_STYLE_SYSLANG && checkboxStyle != markdown.CHECKBOX_STYLE_OBSIDIAN {
+			fmt.Fprintf(os.Stderr, "invalid checkbox style: %s\n", checkboxStyle)
+			os.Exit(1)
+		}
+
+		isObsidian, err := cmd.Flags().GetBool("obsidian")
+		if err != nil {
+			panic(err)
+		}
+		if isObsidian {
+			hasFrontmatter = true
+			shouldRewriteLinks = true
+			checkboxStyle = markdown.CHECKBOX_STYLE_OBSIDIAN
+		}
+
+		if isDaemon {
+			pid, err := startWikiMountDaemon(pidfilePath, logPath)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
 			}
-		}()
-		if err := w.AddRecursive(root); err != nil {
-			log.Fatalln(err)
+			fmt.Printf("mounted %s on %s (pid %d)\n", root, mountPoint, pid)
+			return
+		}
+
+		options := wikivfs.WikiVFSOptions{
+			Writable:      isWritable,
+			CacheSize:     cacheSize,
+			VirtualDirs:   hasVirtualDirs,
+			Views:         views,
+			Frontmatter:   hasFrontmatter,
+			RewriteLinks:  shouldRewriteLinks,
+			CheckboxStyle: checkboxStyle,
+		}
+		if err := serveWikiMount(root, mountPoint, pidfilePath, options); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+	},
+}
 
-		// mount
-		vfs, err := wikivfs.NewWikiVFS(wikiInstance, root, mountPoint)
+var wikiUmountCommand = &cobra.Command{
+	Use:   "umount",
+	Short: "unmount wiki vfs",
+	Run: func(cmd *cobra.Command, args []string) {
+		mountPoint, err := cmd.Flags().GetString("mount")
 		if err != nil {
 			panic(err)
 		}
-		defer vfs.Close()
-		err = vfs.Mount()
+
+		pidfilePath, err := cmd.Flags().GetString("pidfile")
 		if err != nil {
 			panic(err)
 		}
+		if pidfilePath == "" {
+			pidfilePath = getMountPidfilePath(mountPoint)
+		}
+
+		// ask the serving process to unmount and clean up
+		if pid, err := utils.ReadPidfile(pidfilePath); err == nil && utils.IsProcessRunning(pid) {
+			if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			deadline := time.Now().Add(MOUNT_STOP_TIMEOUT)
+			for utils.IsProcessRunning(pid) && time.Now().Before(deadline) {
+				time.Sleep(50 * time.Millisecond)
+			}
+			if !utils.IsProcessRunning(pid) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "process %d did not stop, unmounting %s\n", pid, mountPoint)
+		}
+
+		// no live server, unmount directly (handles stale mounts)
+		os.Remove(pidfilePath)
+		if err := fuse.Unmount(mountPoint); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 	},
 }
 
 func init() {
-	cmd := &cobra.Command{Use: "wiki"}
-
 	wikiListCommand.Flags().Bool("debug", false, "debug parsing time for each nodes")
 	wikiListCommand.Flags().String("type", "", "filter nodes by type")
-	cmd.AddCommand(wikiListCommand)
+	wikiCommand.AddCommand(wikiListCommand)
 
 	wikiResolveCommand.Flags().Bool("strict", false, "will not return the default would-be path for if the node is not found")
-	cmd.AddCommand(wikiResolveCommand)
+	wikiResolveCommand.Flags().BoolP("interactive", "i", false, "pick the node from fuzzy matches")
+	wikiResolveCommand.Flags().Bool("all", false, "print every fuzzy match, best first")
+	wikiCommand.AddCommand(wikiResolveCommand)
 
 	wikiMountCommand.Flags().String("mount", "/tmp/wiki", "mount point")
-	cmd.AddCommand(wikiMountCommand)
-
-	rootCmd.AddCommand(cmd)
+	wikiMountCommand.Flags().Bool("writable", false, "convert markdown saved through the mount back to syslang")
+	wikiMountCommand.Flags().Int("cache-size", wikivfs.DEFAULT_CACHE_SIZE, "maximum number of converted nodes kept in memory")
+	wikiMountCommand.Flags().Bool("virtual-dirs", false, "expose _by-type, _tasks and _meta query directories")
+	wikiMountCommand.Flags().String("views", wikivfs.FILE_VIEW_MARKDOWN, "node views (raw, md, html), overridden per directory by "+wikivfs.VIEWS_CONFIG_FILE+" files")
+	wikiMountCommand.Flags().Bool("frontmatter", false, "prepend node meta as yaml frontmatter")
+	wikiMountCommand.Flags().Bool("rewrite-links", false, "rewrite [[node]] links to paths inside the mount")
+	wikiMountCommand.Flags().String("checkboxes", markdown.CHECKBOX_STYLE_SYSLANG, "task checkbox style (syslang, obsidian)")
+	wikiMountCommand.Flags().Bool("obsidian", false, "enable frontmatter, link rewriting and obsidian checkboxes")
+	wikiMountCommand.Flags().Bool("daemon", false, "mount in the background")
+	wikiMountCommand.Flags().String("pidfile", "", "pidfile path (default: <mount>.pid)")
+	wikiMountCommand.Flags().String("log", "", "log file for --daemon (default: discard)")
+	wikiCommand.AddCommand(wikiMountCommand)
+
+	wikiUmountCommand.Flags().String("mount", "/tmp/wiki", "mount point")
+	wikiUmountCommand.Flags().String("pidfile", "", "pidfile path (default: <mount>.pid)")
+	wikiCommand.AddCommand(wikiUmountCommand)
+
+	rootCmd.AddCommand(wikiCommand)
 }
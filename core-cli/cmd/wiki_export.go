@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"core/export"
+	"fmt"
+	"os"
+
+	local_wiki "github.com/3rd/core/core-lib/wiki/local"
+	"github.com/3rd/core/core-lib/wiki/markdown"
+	"github.com/spf13/cobra"
+)
+
+var wikiExportHTMLCommand = &cobra.Command{
+	Use:   "html",
+	Short: "export the wiki as linked html pages",
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.WIKI_ROOT
+		if len(root) == 0 {
+			panic("WIKI_ROOT not set")
+		}
+
+		outDir, err := cmd.Flags().GetString("out")
+		if err != nil {
+			panic(err)
+		}
+		if outDir == "" {
+			panic("--out not set")
+		}
+
+		wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
+			Root:  root,
+			Parse: "full",
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		if err := export.ExportHTML(wikiInstance, root, outDir); err != nil {
+			panic(err)
+		}
+		fmt.Printf("exported %s to %s\n", root, outDir)
+	},
+}
+
+var wikiExportMarkdownCommand = &cobra.Command{
+	Use:   "markdown",
+	Short: "export every node as markdown",
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.WIKI_ROOT
+		if len(root) == 0 {
+			panic("WIKI_ROOT not set")
+		}
+
+		outDir, err := cmd.Flags().GetString("out")
+		if err != nil {
+			panic(err)
+		}
+		if outDir == "" {
+			panic("--out not set")
+		}
+
+		hasFrontmatter, err := cmd.Flags().GetBool("frontmatter")
+		if err != nil {
+			panic(err)
+		}
+
+		wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
+			Root:  root,
+			Parse: "full",
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		dialect := markdown.Dialect{Frontmatter: hasFrontmatter}
+		if err := export.ExportMarkdown(wikiInstance, root, outDir, dialect); err != nil {
+			panic(err)
+		}
+		fmt.Printf("exported %s to %s\n", root, outDir)
+	},
+}
+
+var wikiImportMarkdownCommand = &cobra.Command{
+	Use:   "markdown <dir>",
+	Short: "convert a markdown directory into syslang nodes",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.WIKI_ROOT
+		if len(root) == 0 {
+			panic("WIKI_ROOT not set")
+		}
+
+		checkboxStyle, err := cmd.Flags().GetString("checkboxes")
+		if err != nil {
+			panic(err)
+		}
+		if checkboxStyle != markdown.CHECKBOX_STYLE_SYSLANG && checkboxStyle != markdown.CHECKBOX_STYLE_OBSIDIAN {
+			fmt.Fprintf(os.Stderr, "invalid checkbox style: %s\n", checkboxStyle)
+			os.Exit(1)
+		}
+
+		overwrite, err := cmd.Flags().GetBool("overwrite")
+		if err != nil {
+			panic(err)
+		}
+
+		isDryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			panic(err)
+		}
+
+		result, err := export.ImportMarkdown(args[0], root, export.ImportOptions{
+			Dialect:   markdown.Dialect{CheckboxStyle: checkboxStyle},
+			Overwrite: overwrite,
+			DryRun:    isDryRun,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		for _, skip := range result.Skipped {
+			fmt.Fprintf(os.Stderr, "skipped %s\n", skip)
+		}
+		for _, issue := range result.Issues {
+			fmt.Fprintln(os.Stderr, issue)
+		}
+		fmt.Printf("imported %d, skipped %d, %d unconverted constructs\n", len(result.Imported), len(result.Skipped), len(result.Issues))
+	},
+}
+
+func init() {
+	wikiExportCommand := &cobra.Command{Use: "export", Short: "export the wiki"}
+
+	wikiExportHTMLCommand.Flags().String("out", "", "output directory")
+	wikiExportCommand.AddCommand(wikiExportHTMLCommand)
+
+	wikiExportMarkdownCommand.Flags().String("out", "", "output directory")
+	wikiExportMarkdownCommand.Flags().Bool("frontmatter", false, "prepend node meta as yaml frontmatter")
+	wikiExportCommand.AddCommand(wikiExportMarkdownCommand)
+
+	wikiCommand.AddCommand(wikiExportCommand)
+
+	wikiImportCommand := &cobra.Command{Use: "import", Short: "import nodes into the wiki"}
+
+	wikiImportMarkdownCommand.Flags().String("checkboxes", markdown.CHECKBOX_STYLE_SYSLANG, "task checkbox style of the source (syslang, obsidian)")
+	wikiImportMarkdownCommand.Flags().Bool("overwrite", false, "replace existing nodes")
+	wikiImportMarkdownCommand.Flags().Bool("dry-run", false, "report without writing")
+	wikiImportCommand.AddCommand(wikiImportMarkdownCommand)
+
+	wikiCommand.AddCommand(wikiImportCommand)
+}
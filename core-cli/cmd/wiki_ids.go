@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	local_wiki "github.com/3rd/core/core-lib/wiki/local"
+	"github.com/spf13/cobra"
+)
+
+var wikiStampIDsCommand = &cobra.Command{
+	Use:   "stamp-ids",
+	Short: "write the current id into the meta block of nodes without one",
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.WIKI_ROOT
+		if len(root) == 0 {
+			panic("WIKI_ROOT not set")
+		}
+
+		isDryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			panic(err)
+		}
+
+		wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
+			Root:  root,
+			Parse: "meta",
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		stamped, err := wikiInstance.StampIDs(isDryRun)
+		if err != nil {
+			panic(err)
+		}
+		for _, node := range stamped {
+			fmt.Printf("%s: %s\n", node.GetPath(), node.GetID())
+		}
+	},
+}
+
+func init() {
+	wikiStampIDsCommand.Flags().Bool("dry-run", false, "list the nodes without writing them")
+	wikiCommand.AddCommand(wikiStampIDsCommand)
+}
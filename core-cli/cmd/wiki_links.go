@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/3rd/core/core-lib/wiki"
+	local_wiki "github.com/3rd/core/core-lib/wiki/local"
+	"github.com/3rd/core/core-lib/wiki/resolve"
+	"github.com/spf13/cobra"
+)
+
+func loadLinkWiki() *local_wiki.LocalWiki {
+	root := env.WIKI_ROOT
+	if len(root) == 0 {
+		panic("WIKI_ROOT not set")
+	}
+	wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
+		Root:  root,
+		Parse: "meta",
+	})
+	if err != nil {
+		panic(err)
+	}
+	return wikiInstance
+}
+
+// getLinkNodeID resolves a node argument given by ID, name, file name or alias
+func getLinkNodeID(wikiInstance wiki.Wiki, target string) string {
+	nodes, err := wikiInstance.GetNodes()
+	if err != nil {
+		panic(err)
+	}
+	if node, ok := resolve.Exact(nodes, target); ok {
+		return node.GetID()
+	}
+	return target
+}
+
+var wikiLinksCommand = &cobra.Command{
+	Use:   "links <node>",
+	Short: "list the nodes a node links to",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		wikiInstance := loadLinkWiki()
+
+		id := getLinkNodeID(wikiInstance, args[0])
+		node, err := wikiInstance.GetNode(id)
+		if err != nil {
+			panic(err)
+		}
+		if node == nil {
+			fmt.Fprintf(os.Stderr, "node not found: %s\n", args[0])
+			os.Exit(1)
+		}
+		links, err := wikiInstance.GetLinks(id)
+		if err != nil {
+			panic(err)
+		}
+		for _, link := range links {
+			target, err := wikiInstance.GetNodeByName(link.Target)
+			if err != nil {
+				panic(err)
+			}
+			if target == nil {
+				fmt.Printf("%s (missing)\n", link.Target)
+				continue
+			}
+			fmt.Println(link.Target)
+		}
+	},
+}
+
+var wikiBacklinksCommand = &cobra.Command{
+	Use:   "backlinks <node>",
+	Short: "list the nodes linking to a node",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		wikiInstance := loadLinkWiki()
+
+		backlinks, err := wikiInstance.GetBacklinks(getLinkNodeID(wikiInstance, args[0]))
+		if err != nil {
+			panic(err)
+		}
+		for _, node := range backlinks {
+			fmt.Println(node.GetID())
+		}
+	},
+}
+
+var wikiOrphansCommand = &cobra.Command{
+	Use:   "orphans",
+	Short: "list the nodes no other node links to",
+	Run: func(cmd *cobra.Command, args []string) {
+		wikiInstance := loadLinkWiki()
+
+		orphans, err := wikiInstance.GetOrphans()
+		if err != nil {
+			panic(err)
+		}
+		for _, node := range orphans {
+			fmt.Println(node.GetID())
+		}
+	},
+}
+
+var wikiGraphCommand = &cobra.Command{
+	Use:   "graph",
+	Short: "export the link graph",
+	Run: func(cmd *cobra.Command, args []string) {
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			panic(err)
+		}
+
+		wikiInstance := loadLinkWiki()
+		graph, err := wikiInstance.GetGraph()
+		if err != nil {
+			panic(err)
+		}
+
+		switch format {
+		case "dot":
+			err = graph.WriteDOT(os.Stdout)
+		case "json":
+			err = graph.WriteJSON(os.Stdout)
+		default:
+			panic(fmt.Sprintf("invalid format: %s", format))
+		}
+		if err != nil {
+			panic(err)
+		}
+	},
+}
+
+func init() {
+	wikiCommand.AddCommand(wikiLinksCommand)
+	wikiCommand.AddCommand(wikiBacklinksCommand)
+	wikiCommand.AddCommand(wikiOrphansCommand)
+
+	wikiGraphCommand.Flags().String("format", "dot", "output format (dot, json)")
+	wikiCommand.AddCommand(wikiGraphCommand)
+}
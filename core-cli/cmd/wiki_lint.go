@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/3rd/core/core-lib/wiki/lint"
+	local_wiki "github.com/3rd/core/core-lib/wiki/local"
+	"github.com/spf13/cobra"
+)
+
+var wikiLintCommand = &cobra.Command{
+	Use:   "lint",
+	Short: "report broken links, orphans, unknown meta values and malformed task lines",
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.WIKI_ROOT
+		if len(root) == 0 {
+			panic("WIKI_ROOT not set")
+		}
+
+		configPath, err := cmd.Flags().GetString("config")
+		if err != nil {
+			panic(err)
+		}
+		if configPath == "" {
+			configPath = filepath.Join(root, lint.CONFIG_FILE)
+		}
+
+		disabled, err := cmd.Flags().GetStringSlice("disable")
+		if err != nil {
+			panic(err)
+		}
+
+		config, err := lint.LoadConfig(configPath)
+		if err != nil {
+			panic(err)
+		}
+		for _, rule := range disabled {
+			if !slices.Contains(lint.Rules, rule) {
+				panic(fmt.Sprintf("invalid lint rule: %s", rule))
+			}
+			config.Disabled = append(config.Disabled, rule)
+		}
+
+		wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
+			Root:  root,
+			Parse: "full",
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		issues, err := lint.Lint(wikiInstance, root, config)
+		if err != nil {
+			panic(err)
+		}
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		if len(issues) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	wikiLintCommand.Flags().String("config", "", "lint config (default: <WIKI_ROOT>/"+lint.CONFIG_FILE+")")
+	wikiLintCommand.Flags().StringSlice("disable", []string{}, "rules to skip (broken-link, orphan, meta-value, task-property)")
+	wikiCommand.AddCommand(wikiLintCommand)
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"core/utils"
+	wikivfs "core/vfs/wiki-vfs"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	local_wiki "github.com/3rd/core/core-lib/wiki/local"
+	"github.com/radovskyb/watcher"
+)
+
+const (
+	MOUNT_START_TIMEOUT = 10 * time.Second
+	MOUNT_STOP_TIMEOUT  = 5 * time.Second
+)
+
+func getMountPidfilePath(mountPoint string) string {
+	return filepath.Clean(mountPoint) + ".pid"
+}
+
+// startWikiMountDaemon re-runs the current mount command without --daemon in a new session,
+// and waits until the child has mounted and written its pidfile
+func startWikiMountDaemon(pidfilePath string, logPath string) (int, error) {
+	if pid, err := utils.ReadPidfile(pidfilePath); err == nil && utils.IsProcessRunning(pid) {
+		return 0, fmt.Errorf("already mounted by process %d (%s)", pid, pidfilePath)
+	}
+	os.Remove(pidfilePath)
+
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	args := []string{}
+	for _, arg := range os.Args[1:] {
+		if arg == "--daemon" || strings.HasPrefix(arg, "--daemon=") {
+			continue
+		}
+		args = append(args, arg)
+	}
+
+	if logPath == "" {
+		logPath = os.DevNull
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer logFile.Close()
+
+	child := exec.Command(executable, args...)
+	child.Stdout = logFile
+	child.Stderr = logFile
+	child.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := child.Start(); err != nil {
+		return 0, err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- child.Wait()
+	}()
+
+	deadline := time.After(MOUNT_START_TIMEOUT)
+	for {
+		select {
+		case err := <-exited:
+			return 0, fmt.Errorf("mount exited during startup: %v", err)
+		case <-deadline:
+			child.Process.Signal(syscall.SIGTERM)
+			return 0, fmt.Errorf("mount did not start within %s", MOUNT_START_TIMEOUT)
+		case <-time.After(50 * time.Millisecond):
+			if pid, err := utils.ReadPidfile(pidfilePath); err == nil && pid == child.Process.Pid {
+				return pid, nil
+			}
+		}
+	}
+}
+
+// serveWikiMount mounts root and serves it until it's unmounted, reloading nodes as files change
+func serveWikiMount(root string, mountPoint string, pidfilePath string, options wikivfs.WikiVFSOptions) error {
+	// get wiki
+	wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
+		Root:  root,
+		Parse: "meta",
+	})
+	if err != nil {
+		return err
+	}
+
+	// mount
+	vfs, err := wikivfs.NewWikiVFS(wikiInstance, root, mountPoint, options)
+	if err != nil {
+		return fmt.Errorf("failed to mount %s: %w", mountPoint, err)
+	}
+	defer vfs.Close()
+
+	if err := utils.WritePidfile(pidfilePath); err != nil {
+		vfs.Unmount()
+		return fmt.Errorf("failed to write pidfile: %w", err)
+	}
+	defer os.Remove(pidfilePath)
+
+	// setup watcher
+	w, err := utils.NewRootWatcher(root, func(event watcher.Event) {
+		if err := wikiInstance.ReloadPath(event.Path); err != nil {
+			log.Printf("failed to reload %s: %v", event.Path, err)
+		}
+		if event.OldPath != "" {
+			if err := wikiInstance.ReloadPath(event.OldPath); err != nil {
+				log.Printf("failed to reload %s: %v", event.OldPath, err)
+			}
+		}
+		vfs.Invalidate(event.Path)
+		if event.OldPath != "" {
+			vfs.Invalidate(event.OldPath)
+		}
+	})
+	if err != nil {
+		vfs.Unmount()
+		return err
+	}
+	go w.Start(100 * time.Millisecond)
+	defer w.Close()
+
+	// unmount on signals, a second signal exits even if the mount is busy
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		if err := vfs.Unmount(); err != nil {
+			log.Printf("failed to unmount %s: %v", mountPoint, err)
+		}
+		<-signals
+		os.Remove(pidfilePath)
+		os.Exit(1)
+	}()
+
+	return vfs.Mount()
+}
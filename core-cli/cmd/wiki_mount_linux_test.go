@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bufio"
+	"core/utils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"bazil.org/fuse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requireFuse skips integration tests on hosts that cannot mount
+func requireFuse(t *testing.T) {
+	if _, err := os.Stat("/dev/fuse"); err != nil {
+		t.Skip("/dev/fuse not available")
+	}
+	if _, err := exec.LookPath("fusermount"); err != nil {
+		t.Skip("fusermount not available")
+	}
+}
+
+// TestCommandProcess runs the command line after "--" when started by coreCommand,
+// --daemon re-executes it with the same arguments, it does nothing in a regular run
+func TestCommandProcess(t *testing.T) {
+	if os.Getenv("CORE_TEST_COMMAND") != "1" {
+		return
+	}
+	for i, arg := range os.Args {
+		if arg == "--" {
+			rootCmd.SetArgs(os.Args[i+1:])
+			break
+		}
+	}
+	Execute()
+	os.Exit(0)
+}
+
+func coreCommand(root string, args ...string) *exec.Cmd {
+	command := exec.Command(os.Args[0], append([]string{"-test.run=^TestCommandProcess$", "--"}, args...)...)
+	command.Env = append(os.Environ(), "CORE_TEST_COMMAND=1", "WIKI_ROOT="+root)
+	return command
+}
+
+func isMounted(t *testing.T, mountPoint string) bool {
+	file, err := os.Open("/proc/self/mounts")
+	require.NoError(t, err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 1 && fields[1] == mountPoint {
+			return true
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return false
+}
+
+func waitForMount(t *testing.T, mountPoint string) {
+	require.Eventually(t, func() bool {
+		entries, err := os.ReadDir(mountPoint)
+		return err == nil && len(entries) > 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestWikiMount(t *testing.T) {
+	requireFuse(t)
+
+	root, err := filepath.Abs("../../core-lib/test-data/wiki/default")
+	require.NoError(t, err)
+
+	t.Run("Unmount on SIGTERM", func(t *testing.T) {
+		mountPoint := t.TempDir()
+		t.Cleanup(func() { fuse.Unmount(mountPoint) })
+		pidfilePath := filepath.Join(t.TempDir(), "mount.pid")
+
+		server := coreCommand(root, "wiki", "mount", "--mount", mountPoint, "--pidfile", pidfilePath)
+		require.NoError(t, server.Start())
+		t.Cleanup(func() { server.Process.Kill() })
+		waitForMount(t, mountPoint)
+
+		pid, err := utils.ReadPidfile(pidfilePath)
+		require.NoError(t, err)
+		assert.Equal(t, server.Process.Pid, pid)
+
+		require.NoError(t, server.Process.Signal(syscall.SIGTERM))
+		exited := make(chan error, 1)
+		go func() { exited <- server.Wait() }()
+		select {
+		case err := <-exited:
+			assert.NoError(t, err)
+		case <-time.After(MOUNT_STOP_TIMEOUT):
+			t.Fatal("mount did not exit after SIGTERM")
+		}
+
+		assert.False(t, isMounted(t, mountPoint))
+		assert.NoFileExists(t, pidfilePath)
+	})
+
+	t.Run("Umount a daemon through its pidfile", func(t *testing.T) {
+		mountPoint := t.TempDir()
+		t.Cleanup(func() { fuse.Unmount(mountPoint) })
+		pidfilePath := getMountPidfilePath(mountPoint)
+
+		output, err := coreCommand(root, "wiki", "mount", "--daemon", "--mount", mountPoint).CombinedOutput()
+		require.NoError(t, err, string(output))
+		pid, err := utils.ReadPidfile(pidfilePath)
+		require.NoError(t, err)
+		t.Cleanup(func() { syscall.Kill(pid, syscall.SIGKILL) })
+		assert.Contains(t, string(output), "(pid "+strconv.Itoa(pid)+")")
+		assert.True(t, utils.IsProcessRunning(pid))
+		waitForMount(t, mountPoint)
+
+		output, err = coreCommand(root, "wiki", "umount", "--mount", mountPoint).CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		assert.False(t, utils.IsProcessRunning(pid))
+		assert.False(t, isMounted(t, mountPoint))
+		assert.NoFileExists(t, pidfilePath)
+	})
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	local_wiki "github.com/3rd/core/core-lib/wiki/local"
+	"github.com/3rd/core/core-lib/wiki/resolve"
+	"github.com/3rd/core/core-lib/wiki/template"
+	"github.com/spf13/cobra"
+)
+
+var wikiMvCommand = &cobra.Command{
+	Use:   "mv <old> <new>",
+	Short: "rename or move a node and update references to it",
+	Long: `Rename or move a node and rewrite the links of every node referencing it.
+
+A <new> value containing a "/" moves the file relative to WIKI_ROOT.
+Otherwise the node is renamed: titled nodes get a new @meta title, and their file is renamed
+too when it was named after the title, untitled nodes get a new file name.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.WIKI_ROOT
+		if len(root) == 0 {
+			panic("WIKI_ROOT not set")
+		}
+
+		isDryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			panic(err)
+		}
+
+		wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
+			Root:  root,
+			Parse: "meta",
+		})
+		if err != nil {
+			panic(err)
+		}
+		nodes, err := wikiInstance.GetNodes()
+		if err != nil {
+			panic(err)
+		}
+
+		node, ok := resolve.Exact(nodes, args[0])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "node not found: %s\n", args[0])
+			os.Exit(1)
+		}
+
+		target := args[1]
+		options := local_wiki.RenameOptions{DryRun: isDryRun}
+		if strings.Contains(target, "/") {
+			options.Path = filepath.Join(root, target)
+		} else if title := node.GetMeta()["title"]; title != "" {
+			options.Title = target
+			if filepath.Base(node.GetPath()) == template.Slugify(title) {
+				options.Path = filepath.Join(filepath.Dir(node.GetPath()), template.Slugify(target))
+			}
+		} else {
+			options.Path = filepath.Join(filepath.Dir(node.GetPath()), target)
+		}
+
+		result, err := wikiInstance.RenameNode(node.GetID(), options)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if result.NewPath != result.OldPath {
+			fmt.Printf("%s -> %s\n", result.OldPath, result.NewPath)
+		}
+		if result.NewName != result.OldName {
+			fmt.Printf("%s -> %s\n", result.OldName, result.NewName)
+		}
+		if result.NewID != result.OldID {
+			fmt.Printf("id %s -> %s\n", result.OldID, result.NewID)
+		}
+		for _, change := range result.Changes {
+			fmt.Printf("%s: %d links\n", change.Path, change.Links)
+		}
+	},
+}
+
+func init() {
+	wikiMvCommand.Flags().Bool("dry-run", false, "show the changes without writing them")
+	wikiCommand.AddCommand(wikiMvCommand)
+}
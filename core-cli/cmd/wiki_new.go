@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	local_wiki "github.com/3rd/core/core-lib/wiki/local"
+	"github.com/3rd/core/core-lib/wiki/template"
+	"github.com/spf13/cobra"
+)
+
+var wikiNewCommand = &cobra.Command{
+	Use:   "new <name>",
+	Short: "create a node from a template",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.WIKI_ROOT
+		if len(root) == 0 {
+			panic("WIKI_ROOT not set")
+		}
+
+		nodeType, err := cmd.Flags().GetString("type")
+		if err != nil {
+			panic(err)
+		}
+		templateName, err := cmd.Flags().GetString("template")
+		if err != nil {
+			panic(err)
+		}
+		templatesDir, err := cmd.Flags().GetString("templates")
+		if err != nil {
+			panic(err)
+		}
+		if templatesDir == "" {
+			templatesDir = filepath.Join(root, template.DEFAULT_DIR)
+		}
+
+		name := args[0]
+		slug := template.Slugify(name)
+		if slug == "" {
+			fmt.Fprintf(os.Stderr, "invalid node name: %q\n", name)
+			os.Exit(1)
+		}
+
+		directory, err := template.GetDirectory(templatesDir, nodeType)
+		if err != nil {
+			panic(err)
+		}
+		id := filepath.ToSlash(filepath.Join(directory, slug))
+
+		text, err := template.Load(templatesDir, templateName, nodeType)
+		if err != nil {
+			panic(err)
+		}
+		content, err := template.Render(text, template.NewData(id, name, nodeType, time.Now()))
+		if err != nil {
+			panic(err)
+		}
+
+		wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
+			Root:  root,
+			Parse: "meta",
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		node, err := wikiInstance.CreateNode(name, filepath.Join(root, directory, slug), content)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(node.GetPath())
+	},
+}
+
+func init() {
+	wikiNewCommand.Flags().String("type", "", "node type, selects the default template and directory")
+	wikiNewCommand.Flags().String("template", "", "template name, defaults to the type template")
+	wikiNewCommand.Flags().String("templates", "", "templates directory (default $WIKI_ROOT/"+template.DEFAULT_DIR+")")
+	wikiCommand.AddCommand(wikiNewCommand)
+}
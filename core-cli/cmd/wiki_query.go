@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/3rd/core/core-lib/wiki"
+	local_wiki "github.com/3rd/core/core-lib/wiki/local"
+	"github.com/spf13/cobra"
+)
+
+var wikiQueryCommand = &cobra.Command{
+	Use:   "query <query>",
+	Short: "list nodes matching a meta query",
+	Long: `List nodes matching every clause of a meta query, e.g.
+
+  core wiki query 'type=project status!=archived owner~alice due<=today+7d'
+
+Operators: = and != (comma separated alternatives), ~ and !~ (case-insensitive substring),
+<, <=, > and >= (dates, numbers, then text). A bare key matches nodes having it, !key nodes
+missing it. Dates accept today, today+Nd and today-Nw.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.WIKI_ROOT
+		if len(root) == 0 {
+			panic("WIKI_ROOT not set")
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			panic(err)
+		}
+		columns, err := cmd.Flags().GetStringSlice("columns")
+		if err != nil {
+			panic(err)
+		}
+
+		filter, err := wiki.ParseQuery(strings.Join(args, " "), time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
+			Root:  root,
+			Parse: "meta",
+		})
+		if err != nil {
+			panic(err)
+		}
+		nodes, err := wikiInstance.FindNodes(filter)
+		if err != nil {
+			panic(err)
+		}
+
+		switch format {
+		case "id":
+			for _, node := range nodes {
+				fmt.Println(node.GetID())
+			}
+		case "path":
+			for _, node := range nodes {
+				fmt.Println(node.GetPath())
+			}
+		case "table":
+			writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(writer, strings.ToUpper(strings.Join(append([]string{"id"}, columns...), "\t")))
+			for _, node := range nodes {
+				row := []string{node.GetID()}
+				for _, column := range columns {
+					value, _ := wiki.GetMetaValue(node, column)
+					row = append(row, value)
+				}
+				fmt.Fprintln(writer, strings.Join(row, "\t"))
+			}
+			writer.Flush()
+		case "json":
+			data, err := json.MarshalIndent(wiki.NewJSONNodeList(nodes), "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(data))
+		default:
+			panic(fmt.Sprintf("invalid format: %s", format))
+		}
+	},
+}
+
+func init() {
+	wikiQueryCommand.Flags().String("format", "id", "output format (id, path, table, json)")
+	wikiQueryCommand.Flags().StringSlice("columns", []string{"type"}, "meta keys shown by the table format")
+	wikiCommand.AddCommand(wikiQueryCommand)
+}
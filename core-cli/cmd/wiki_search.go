@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	local_wiki "github.com/3rd/core/core-lib/wiki/local"
+	"github.com/spf13/cobra"
+)
+
+var wikiSearchCommand = &cobra.Command{
+	Use:   "search <query>",
+	Short: "full-text search, supports \"phrases\", prefix* and meta:/task: scopes",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.WIKI_ROOT
+		if len(root) == 0 {
+			panic("WIKI_ROOT not set")
+		}
+
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			panic(err)
+		}
+
+		wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
+			Root:  root,
+			Parse: "meta",
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		results, err := wikiInstance.Search(strings.Join(args, " "), limit)
+		if err != nil {
+			panic(err)
+		}
+		for _, result := range results {
+			fmt.Printf("%s:%d: %s\n", result.Path, result.Line, result.Snippet)
+		}
+		if len(results) == 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	wikiSearchCommand.Flags().Int("limit", 20, "maximum number of results, 0 for all")
+	wikiCommand.AddCommand(wikiSearchCommand)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	local_wiki "github.com/3rd/core/core-lib/wiki/local"
+	"github.com/3rd/core/core-lib/wiki/schema"
+	"github.com/spf13/cobra"
+)
+
+var wikiValidateCommand = &cobra.Command{
+	Use:   "validate",
+	Short: "validate node meta against the schema of their type",
+	Run: func(cmd *cobra.Command, args []string) {
+		root := env.WIKI_ROOT
+		if len(root) == 0 {
+			panic("WIKI_ROOT not set")
+		}
+
+		schemaPath, err := cmd.Flags().GetString("schema")
+		if err != nil {
+			panic(err)
+		}
+		if schemaPath == "" {
+			schemaPath = filepath.Join(root, schema.SCHEMA_FILE)
+		}
+
+		nodeSchema, err := schema.LoadSchema(schemaPath)
+		if err != nil {
+			panic(err)
+		}
+
+		wikiInstance, err := local_wiki.NewLocalWiki(local_wiki.LocalWikiConfig{
+			Root:  root,
+			Parse: "meta",
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		issues, err := schema.Validate(wikiInstance, nodeSchema)
+		if err != nil {
+			panic(err)
+		}
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		if len(issues) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	wikiValidateCommand.Flags().String("schema", "", "schema file (default: <WIKI_ROOT>/"+schema.SCHEMA_FILE+")")
+	wikiCommand.AddCommand(wikiValidateCommand)
+}
@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const DIAL_TIMEOUT = 100 * time.Millisecond
+const QUERY_TIMEOUT = 5 * time.Second
+
+// Query sends a request to the daemon, relative roots are resolved against the working directory of the caller.
+// A daemon serving another root returns ErrRootMismatch without a response.
+func Query(socketPath string, request Request) (*Response, error) {
+	if request.Root != "" {
+		request.Root = NormalizeRoot(request.Root)
+	}
+
+	conn, err := net.DialTimeout("unix", socketPath, DIAL_TIMEOUT)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(QUERY_TIMEOUT))
+
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return nil, err
+	}
+
+	response := Response{}
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&response); err != nil {
+		return nil, err
+	}
+	if response.RootMismatch {
+		return nil, fmt.Errorf("%w: %s", ErrRootMismatch, response.Error)
+	}
+	if response.Error != "" {
+		return &response, errors.New(response.Error)
+	}
+	return &response, nil
+}
+
+func IsRunning(socketPath string) bool {
+	_, err := Query(socketPath, Request{Command: COMMAND_PING})
+	return err == nil
+}
@@ -0,0 +1,64 @@
+package daemon
+
+import (
+	"core/utils"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+type COMMAND string
+
+const (
+	COMMAND_PING    COMMAND = "ping"
+	COMMAND_CURRENT COMMAND = "current"
+	COMMAND_ACTIVE  COMMAND = "active"
+	COMMAND_START   COMMAND = "start"
+	COMMAND_STOP    COMMAND = "stop"
+	COMMAND_REPORT  COMMAND = "report"
+)
+
+// Request is sent as a single JSON line, one request per connection.
+type Request struct {
+	Command COMMAND `json:"command"`
+	// Root must match the daemon root when set, so clients never read another wiki
+	Root        string `json:"root,omitempty"`
+	NodeID      string `json:"nodeId,omitempty"`
+	LineNumber  uint32 `json:"lineNumber,omitempty"`
+	IncludeDone bool   `json:"includeDone,omitempty"`
+}
+
+// ErrRootMismatch is returned by Query when the daemon serves another root, clients should run locally instead
+var ErrRootMismatch = errors.New("root mismatch")
+
+type Response struct {
+	Error        string             `json:"error,omitempty"`
+	RootMismatch bool               `json:"rootMismatch,omitempty"`
+	Root         string             `json:"root,omitempty"`
+	Current      *utils.CurrentTask `json:"current,omitempty"`
+	Active       *utils.ActiveTasks `json:"active,omitempty"`
+	Report       *utils.Report      `json:"report,omitempty"`
+}
+
+// NormalizeRoot resolves a root to a clean absolute path without symlinks so equal roots compare equal
+func NormalizeRoot(root string) string {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return filepath.Clean(root)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+func GetSocketPath(override string) string {
+	if override != "" {
+		return override
+	}
+	if runtimeDir, ok := os.LookupEnv("XDG_RUNTIME_DIR"); ok && runtimeDir != "" {
+		return filepath.Join(runtimeDir, "core.sock")
+	}
+	return filepath.Join(os.TempDir(), "core-"+strconv.Itoa(os.Getuid())+".sock")
+}
@@ -0,0 +1,207 @@
+package daemon
+
+import (
+	"bufio"
+	"core/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/3rd/core/core-lib/wiki"
+	"github.com/3rd/core/core-lib/wiki/local"
+	"github.com/radovskyb/watcher"
+)
+
+type ServerConfig struct {
+	Root       string
+	SocketPath string
+}
+
+type Server struct {
+	config   ServerConfig
+	wiki     wiki.Wiki
+	mutex    sync.RWMutex
+	watcher  *watcher.Watcher
+	listener net.Listener
+}
+
+func NewServer(config ServerConfig) (*Server, error) {
+	config.Root = NormalizeRoot(config.Root)
+	wikiInstance, err := local.NewLocalWiki(local.LocalWikiConfig{
+		Root:  config.Root,
+		Parse: local.PARSE_MODE_META,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	server := Server{
+		config: config,
+		wiki:   wikiInstance,
+	}
+
+	server.watcher, err = utils.NewRootWatcher(config.Root, func(event watcher.Event) {
+		server.reload()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &server, nil
+}
+
+func (s *Server) reload() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.wiki.Reload(); err != nil {
+		log.Println(err)
+	}
+}
+
+// Serve listens on the socket and blocks until Close is called.
+func (s *Server) Serve() error {
+	// a socket file without a listener is left over from a crash
+	if _, err := os.Stat(s.config.SocketPath); err == nil {
+		if IsRunning(s.config.SocketPath) {
+			return fmt.Errorf("daemon already running on %s", s.config.SocketPath)
+		}
+		if err := os.Remove(s.config.SocketPath); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("unix", s.config.SocketPath)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(s.config.SocketPath, 0o600); err != nil {
+		listener.Close()
+		return err
+	}
+	s.listener = listener
+
+	go s.watcher.Start(time.Millisecond * 100)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handleConnection(conn)
+	}
+}
+
+func (s *Server) Close() {
+	s.watcher.Close()
+	if s.listener != nil {
+		s.listener.Close()
+	}
+}
+
+func (s *Server) handleConnection(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(QUERY_TIMEOUT))
+
+	request := Request{}
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&request); err != nil {
+		json.NewEncoder(conn).Encode(Response{Error: err.Error()})
+		return
+	}
+
+	response := s.Handle(request)
+	if err := json.NewEncoder(conn).Encode(response); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *Server) Handle(request Request) Response {
+	if request.Root != "" && NormalizeRoot(request.Root) != s.config.Root {
+		return Response{Error: fmt.Sprintf("root mismatch: daemon serves %s", s.config.Root), RootMismatch: true}
+	}
+
+	now := time.Now()
+
+	switch request.Command {
+	case COMMAND_PING:
+		return Response{Root: s.config.Root}
+
+	case COMMAND_CURRENT:
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+		nodes, _ := s.wiki.GetNodes()
+		task := utils.FindCurrentTask(nodes)
+		if task == nil {
+			return Response{Root: s.config.Root}
+		}
+		current := utils.NewCurrentTask(task, now)
+		return Response{Root: s.config.Root, Current: &current}
+
+	case COMMAND_ACTIVE:
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+		nodes, _ := s.wiki.GetNodes()
+		active, done := utils.FindActiveTasks(nodes, request.IncludeDone, now)
+		result := utils.NewActiveTasks(active, done, now)
+		return Response{Root: s.config.Root, Active: &result}
+
+	case COMMAND_REPORT:
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+		nodes, _ := s.wiki.GetNodes()
+		report := utils.NewReport(utils.GetReport(nodes, now), now)
+		return Response{Root: s.config.Root, Report: &report}
+
+	case COMMAND_START:
+		return s.mutate(func() error {
+			node, err := s.wiki.GetNode(request.NodeID)
+			if err != nil {
+				return err
+			}
+			if node == nil {
+				return fmt.Errorf("node not found: %s", request.NodeID)
+			}
+			for _, task := range node.GetTasks() {
+				if task.LineNumber == request.LineNumber {
+					if task.IsInProgress() {
+						return errors.New("task already in progress")
+					}
+					return s.wiki.StartTaskSession(task, now)
+				}
+			}
+			return fmt.Errorf("no task at %s:%d", request.NodeID, request.LineNumber)
+		})
+
+	case COMMAND_STOP:
+		return s.mutate(func() error {
+			nodes, _ := s.wiki.GetNodes()
+			task := utils.FindOpenSessionTask(nodes)
+			if task == nil {
+				return errors.New("no task in progress")
+			}
+			return s.wiki.StopTaskSession(task, now)
+		})
+	}
+
+	return Response{Error: fmt.Sprintf("unknown command: %s", request.Command)}
+}
+
+// mutate runs a file change with exclusive access and reloads right away instead of waiting for the watcher
+func (s *Server) mutate(fn func() error) Response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := fn(); err != nil {
+		return Response{Error: err.Error()}
+	}
+	if err := s.wiki.Reload(); err != nil {
+		return Response{Error: err.Error()}
+	}
+	return Response{Root: s.config.Root}
+}
@@ -0,0 +1,191 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/3rd/core/core-lib/wiki"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestDaemon serves root on a socket in a short temporary directory, unix socket paths are length limited
+func startTestDaemon(t *testing.T, root string) string {
+	dir, err := os.MkdirTemp("", "core")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socketPath := filepath.Join(dir, "core.sock")
+
+	server, err := NewServer(ServerConfig{Root: root, SocketPath: socketPath})
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- server.Serve() }()
+	t.Cleanup(func() {
+		server.Close()
+		assert.NoError(t, <-done)
+	})
+
+	require.Eventually(t, func() bool { return IsRunning(socketPath) }, time.Second, 10*time.Millisecond)
+	return socketPath
+}
+
+func writeTaskNode(t *testing.T, root string, content string) string {
+	path := filepath.Join(root, "project")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func formatSessionTime(t time.Time) string {
+	return fmt.Sprintf("%04d.%02d.%02d %02d:%02d", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute())
+}
+
+func TestDaemon(t *testing.T) {
+	now := time.Now()
+	today := now.Format("2006.01.02")
+	if now.Hour() == 0 && now.Minute() < 5 {
+		t.Skip("the session fixtures need a few minutes of today")
+	}
+
+	// an active task with an open session, a task done today and an idle task
+	tasksContent := "@meta\n  type: project\n@end\n\n* Tasks\n" +
+		"  [-] active task\n    Session: " + formatSessionTime(now.Add(-2*time.Minute)) + "\n" +
+		"  [x] done task\n    Session: " + formatSessionTime(now.Add(-4*time.Minute)) + "-" + now.Add(-3*time.Minute).Format("15:04") + "\n" +
+		"  [ ] idle task\n"
+
+	t.Run("Current", func(t *testing.T) {
+		root := t.TempDir()
+		writeTaskNode(t, root, tasksContent)
+		socketPath := startTestDaemon(t, root)
+
+		response, err := Query(socketPath, Request{Command: COMMAND_CURRENT, Root: root})
+		require.NoError(t, err)
+		require.NotNil(t, response.Current)
+		assert.Equal(t, wiki.JSON_SCHEMA_VERSION, response.Current.SchemaVersion)
+		assert.Equal(t, "active task", response.Current.Task.Text)
+		assert.Equal(t, "project", response.Current.Node.ID)
+		assert.GreaterOrEqual(t, response.Current.Elapsed, int64(60))
+	})
+
+	t.Run("Active", func(t *testing.T) {
+		root := t.TempDir()
+		writeTaskNode(t, root, tasksContent)
+		socketPath := startTestDaemon(t, root)
+
+		response, err := Query(socketPath, Request{Command: COMMAND_ACTIVE, Root: root})
+		require.NoError(t, err)
+		require.NotNil(t, response.Active)
+		assert.Equal(t, wiki.JSON_SCHEMA_VERSION, response.Active.SchemaVersion)
+		require.Len(t, response.Active.ActiveTasks, 1)
+		assert.Equal(t, "active task", response.Active.ActiveTasks[0].Text)
+		assert.Empty(t, response.Active.DoneTasks)
+
+		response, err = Query(socketPath, Request{Command: COMMAND_ACTIVE, Root: root, IncludeDone: true})
+		require.NoError(t, err)
+		require.Len(t, response.Active.DoneTasks, 1)
+		assert.Equal(t, "done task", response.Active.DoneTasks[0].Text)
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		root := t.TempDir()
+		writeTaskNode(t, root, tasksContent)
+		socketPath := startTestDaemon(t, root)
+
+		response, err := Query(socketPath, Request{Command: COMMAND_REPORT, Root: root})
+		require.NoError(t, err)
+		require.NotNil(t, response.Report)
+		assert.Equal(t, wiki.JSON_SCHEMA_VERSION, response.Report.SchemaVersion)
+		require.Len(t, response.Report.Entries, 2)
+		assert.Equal(t, "active task", response.Report.Entries[0].Text)
+		assert.Equal(t, "done task", response.Report.Entries[1].Text)
+		assert.Equal(t, int64(60), response.Report.Entries[1].Duration)
+	})
+
+	t.Run("Start and stop", func(t *testing.T) {
+		root := t.TempDir()
+		path := writeTaskNode(t, root, "@meta\n  type: project\n@end\n\n* Tasks\n  [ ] idle task\n")
+		socketPath := startTestDaemon(t, root)
+
+		_, err := Query(socketPath, Request{Command: COMMAND_START, Root: root, NodeID: "project", LineNumber: 5})
+		require.NoError(t, err)
+		response, err := Query(socketPath, Request{Command: COMMAND_CURRENT, Root: root})
+		require.NoError(t, err)
+		require.NotNil(t, response.Current)
+		assert.Equal(t, "idle task", response.Current.Task.Text)
+
+		// errors are reported with the response so clients don't fall back to a local run
+		response, err = Query(socketPath, Request{Command: COMMAND_START, Root: root, NodeID: "project", LineNumber: 5})
+		require.Error(t, err)
+		require.NotNil(t, response)
+		assert.Equal(t, "task already in progress", response.Error)
+
+		_, err = Query(socketPath, Request{Command: COMMAND_STOP, Root: root})
+		require.NoError(t, err)
+		response, err = Query(socketPath, Request{Command: COMMAND_CURRENT, Root: root})
+		require.NoError(t, err)
+		assert.Nil(t, response.Current)
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Regexp(t, `\n    Session: `+today+` \d{2}:\d{2}-\d{2}:\d{2}\n$`, string(content))
+
+		response, err = Query(socketPath, Request{Command: COMMAND_STOP, Root: root})
+		require.Error(t, err)
+		require.NotNil(t, response)
+		assert.Equal(t, "no task in progress", response.Error)
+	})
+
+	t.Run("Root mismatch", func(t *testing.T) {
+		root := t.TempDir()
+		writeTaskNode(t, root, tasksContent)
+		socketPath := startTestDaemon(t, root)
+
+		response, err := Query(socketPath, Request{Command: COMMAND_CURRENT, Root: t.TempDir()})
+		assert.ErrorIs(t, err, ErrRootMismatch)
+		assert.Nil(t, response)
+
+		// the same root spelled differently is served
+		link := filepath.Join(t.TempDir(), "link")
+		require.NoError(t, os.Symlink(root, link))
+		cwd, err := os.Getwd()
+		require.NoError(t, err)
+		relative, err := filepath.Rel(cwd, root)
+		require.NoError(t, err)
+		for _, spelling := range []string{root + "/", root + "/./", relative, link} {
+			response, err := Query(socketPath, Request{Command: COMMAND_CURRENT, Root: spelling})
+			require.NoError(t, err, spelling)
+			assert.NotNil(t, response.Current, spelling)
+		}
+	})
+
+	t.Run("Fallback without a daemon", func(t *testing.T) {
+		socketPath := filepath.Join(t.TempDir(), "missing.sock")
+
+		response, err := Query(socketPath, Request{Command: COMMAND_CURRENT})
+		assert.Error(t, err)
+		assert.Nil(t, response)
+		assert.False(t, IsRunning(socketPath))
+	})
+
+	t.Run("Stop the task with an open session", func(t *testing.T) {
+		root := t.TempDir()
+		// the scheduled task comes first and is in progress all day, but has no session to stop
+		path := writeTaskNode(t, root, "@meta\n  type: project\n@end\n\n* Tasks\n  [ ] scheduled\n    Schedule: "+today+" 00:00\n  [ ] started\n")
+		socketPath := startTestDaemon(t, root)
+
+		_, err := Query(socketPath, Request{Command: COMMAND_START, Root: root, NodeID: "project", LineNumber: 7})
+		require.NoError(t, err)
+		_, err = Query(socketPath, Request{Command: COMMAND_STOP, Root: root})
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		lines := strings.Split(string(content), "\n")
+		assert.Equal(t, "    Schedule: "+today+" 00:00", lines[6])
+		assert.Equal(t, "  [ ] started", lines[7])
+		assert.Regexp(t, `^    Session: `+today+` \d{2}:\d{2}-\d{2}:\d{2}$`, lines[8])
+	})
+}
@@ -0,0 +1,222 @@
+// Package export writes offline snapshots of a wiki.
+package export
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"core/utils"
+
+	"github.com/3rd/core/core-lib/wiki"
+	"github.com/3rd/core/core-lib/wiki/markdown"
+)
+
+const (
+	HTML_INDEX_FILE = "index.html"
+	HTML_TASKS_DIR  = "tasks"
+	HTML_UNTYPED    = "untyped"
+)
+
+// htmlSite holds the page layout of an export, all hrefs are relative to the output directory,
+// pages are keyed by node path, links and backlinks by node ID since titles aren't unique
+type htmlSite struct {
+	root      string
+	outDir    string
+	nodes     []wiki.Node
+	pages     map[string]string
+	links     map[string]map[string]string
+	backlinks map[string][]wiki.Node
+}
+
+func getNodeSlug(root string, node wiki.Node) string {
+	path, err := filepath.Rel(root, node.GetPath())
+	if err != nil {
+		path = filepath.Base(node.GetPath())
+	}
+	return filepath.ToSlash(path)
+}
+
+// href returns the path of target relative to the page at from
+func href(from string, target string) string {
+	relative, err := filepath.Rel(filepath.Dir(filepath.FromSlash(from)), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(relative)
+}
+
+func formatDuration(duration time.Duration) string {
+	return fmt.Sprintf("%dh%02dm", int(duration.Hours()), int(duration.Minutes())%60)
+}
+
+func newHTMLSite(wikiInstance wiki.Wiki, root string, outDir string) (*htmlSite, error) {
+	nodes, err := wikiInstance.GetNodes()
+	if err != nil {
+		return nil, err
+	}
+	site := htmlSite{
+		root:      root,
+		outDir:    outDir,
+		nodes:     nodes,
+		pages:     map[string]string{},
+		links:     map[string]map[string]string{},
+		backlinks: map[string][]wiki.Node{},
+	}
+
+	for _, node := range nodes {
+		site.pages[node.GetPath()] = getNodeSlug(root, node) + ".html"
+	}
+	for _, node := range nodes {
+		backlinks, err := wikiInstance.GetBacklinks(node.GetID())
+		if err != nil {
+			return nil, err
+		}
+		site.backlinks[node.GetID()] = backlinks
+
+		// link targets are names, resolved once to the page of the node the wiki picks
+		targets := map[string]string{}
+		for _, link := range node.GetLinks() {
+			if _, ok := targets[link.Target]; ok {
+				continue
+			}
+			target, err := wikiInstance.GetNodeByName(link.Target)
+			if err != nil {
+				return nil, err
+			}
+			if target != nil {
+				targets[link.Target] = site.pages[target.GetPath()]
+			}
+		}
+		site.links[node.GetID()] = targets
+	}
+	return &site, nil
+}
+
+func (s *htmlSite) write(page string, title string, body string) error {
+	path := filepath.Join(s.outDir, filepath.FromSlash(page))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	nav := fmt.Sprintf("<nav><a href=\"%s\">index</a></nav>\n", href(page, HTML_INDEX_FILE))
+	return os.WriteFile(path, []byte(markdown.HTMLPage(title, nav+body)), 0o644)
+}
+
+func (s *htmlSite) link(from string, node wiki.Node) string {
+	return fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(href(from, s.pages[node.GetPath()])), html.EscapeString(node.GetName()))
+}
+
+func (s *htmlSite) writeNode(node wiki.Node) error {
+	page := s.pages[node.GetPath()]
+	linkHref := func(name string) (string, bool) {
+		target, ok := s.links[node.GetID()][name]
+		if !ok {
+			return "", false
+		}
+		return href(page, target), true
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(markdown.ToHTML(node.ToMarkdown(), markdown.HTMLOptions{LinkHref: linkHref}))
+
+	if utils.IsTaskNode(node) && len(node.GetTasks()) > 0 {
+		fmt.Fprintf(&builder, "<p><a href=\"%s\">tasks</a></p>\n", href(page, s.getTasksPage(node)))
+	}
+
+	if backlinks := s.backlinks[node.GetID()]; len(backlinks) > 0 {
+		builder.WriteString("<section class=\"backlinks\">\n<h2>Backlinks</h2>\n<ul>\n")
+		for _, source := range backlinks {
+			builder.WriteString("<li>" + s.link(page, source) + "</li>\n")
+		}
+		builder.WriteString("</ul>\n</section>\n")
+	}
+
+	return s.write(page, node.GetName(), builder.String())
+}
+
+func (s *htmlSite) getTasksPage(node wiki.Node) string {
+	return HTML_TASKS_DIR + "/" + strings.ReplaceAll(getNodeSlug(s.root, node), "/", "-") + ".html"
+}
+
+func (s *htmlSite) writeTasks(node wiki.Node) error {
+	page := s.getTasksPage(node)
+	tasks := node.GetTasks()
+
+	var total time.Duration
+	builder := strings.Builder{}
+	fmt.Fprintf(&builder, "<h1>%s tasks</h1>\n", s.link(page, node))
+	builder.WriteString("<table>\n<tr><th>status</th><th>task</th><th>sessions</th><th>time</th></tr>\n")
+	for _, task := range tasks {
+		duration := task.GetTotalSessionTime()
+		total += duration
+		status := string(task.Status)
+		if task.IsInProgress() {
+			status = "in progress"
+		}
+		fmt.Fprintf(&builder, "<tr class=\"task-%s\"><td>%s</td><td>%s</td><td>%d</td><td>%s</td></tr>\n",
+			task.Status, status, html.EscapeString(task.Text), len(task.Sessions), formatDuration(duration))
+	}
+	builder.WriteString("</table>\n")
+	fmt.Fprintf(&builder, "<p>total: %s</p>\n", formatDuration(total))
+
+	return s.write(page, node.GetName()+" tasks", builder.String())
+}
+
+func (s *htmlSite) writeIndex() error {
+	byType := map[string][]wiki.Node{}
+	for _, node := range s.nodes {
+		nodeType := node.GetMeta()["type"]
+		if nodeType == "" {
+			nodeType = HTML_UNTYPED
+		}
+		byType[nodeType] = append(byType[nodeType], node)
+	}
+	types := []string{}
+	for nodeType := range byType {
+		types = append(types, nodeType)
+	}
+	sort.Strings(types)
+
+	builder := strings.Builder{}
+	builder.WriteString("<h1>Index</h1>\n")
+	for _, nodeType := range types {
+		nodes := byType[nodeType]
+		sort.Slice(nodes, func(i, j int) bool {
+			return nodes[i].GetName() < nodes[j].GetName()
+		})
+		fmt.Fprintf(&builder, "<h2>%s</h2>\n<ul>\n", html.EscapeString(nodeType))
+		for _, node := range nodes {
+			builder.WriteString("<li>" + s.link(HTML_INDEX_FILE, node))
+			if utils.IsTaskNode(node) && len(node.GetTasks()) > 0 {
+				fmt.Fprintf(&builder, " (<a href=\"%s\">tasks</a>)", html.EscapeString(href(HTML_INDEX_FILE, s.getTasksPage(node))))
+			}
+			builder.WriteString("</li>\n")
+		}
+		builder.WriteString("</ul>\n")
+	}
+	return s.write(HTML_INDEX_FILE, "Index", builder.String())
+}
+
+// ExportHTML renders every node of the wiki into linked HTML pages under outDir,
+// with an index by node type and a task page for every project
+func ExportHTML(wikiInstance wiki.Wiki, root string, outDir string) error {
+	site, err := newHTMLSite(wikiInstance, root, outDir)
+	if err != nil {
+		return err
+	}
+	for _, node := range site.nodes {
+		if err := site.writeNode(node); err != nil {
+			return err
+		}
+		if utils.IsTaskNode(node) && len(node.GetTasks()) > 0 {
+			if err := site.writeTasks(node); err != nil {
+				return err
+			}
+		}
+	}
+	return site.writeIndex()
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/3rd/core/core-lib/wiki/local"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportHTML(t *testing.T) {
+	root, err := filepath.Abs("../../core-lib/test-data/wiki/export")
+	require.NoError(t, err)
+	wikiInstance, err := local.NewLocalWiki(local.LocalWikiConfig{Root: root, Parse: local.PARSE_MODE_FULL})
+	require.NoError(t, err)
+
+	outDir := t.TempDir()
+	require.NoError(t, ExportHTML(wikiInstance, root, outDir))
+
+	t.Run("Index groups nodes by type", func(t *testing.T) {
+		index, err := os.ReadFile(filepath.Join(outDir, HTML_INDEX_FILE))
+		require.NoError(t, err)
+		assert.Contains(t, string(index), "<h2>project</h2>")
+		assert.Contains(t, string(index), "<h2>note</h2>")
+		assert.Contains(t, string(index), `<a href="project-a.html">Project A</a>`)
+		assert.Contains(t, string(index), `<a href="notes/note-1.html">note-1</a>`)
+		assert.Contains(t, string(index), `<a href="tasks/project-a.html">tasks</a>`)
+	})
+
+	t.Run("Node pages link tasks and backlinks", func(t *testing.T) {
+		page, err := os.ReadFile(filepath.Join(outDir, "project-a.html"))
+		require.NoError(t, err)
+		assert.Contains(t, string(page), `<a href="index.html">index</a>`)
+		assert.Contains(t, string(page), `<a href="tasks/project-a.html">tasks</a>`)
+		assert.Contains(t, string(page), `<h2>Backlinks</h2>`)
+		assert.Contains(t, string(page), `<a href="notes/note-1.html">note-1</a>`)
+	})
+
+	t.Run("Links resolve relative to the page", func(t *testing.T) {
+		page, err := os.ReadFile(filepath.Join(outDir, "notes", "note-1.html"))
+		require.NoError(t, err)
+		assert.Contains(t, string(page), `<a href="../index.html">index</a>`)
+		assert.Contains(t, string(page), `<a href="../project-a.html">Project A</a>`)
+	})
+
+	t.Run("Task pages list status and time", func(t *testing.T) {
+		page, err := os.ReadFile(filepath.Join(outDir, HTML_TASKS_DIR, "project-a.html"))
+		require.NoError(t, err)
+		assert.Contains(t, string(page), `<a href="../project-a.html">Project A</a>`)
+		assert.Contains(t, string(page), `<td>task 2-2</td><td>1</td><td>1h00m</td>`)
+		assert.Contains(t, string(page), "<th>status</th>")
+		assert.Contains(t, string(page), "total: ")
+	})
+
+	t.Run("Nodes with the same title keep their own links and backlinks", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "a"), 0o755))
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "b"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "a", "dup"), []byte("@meta\n  title: Dup\n@end\n\n* A\n  [[target]]\n"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "b", "dup"), []byte("@meta\n  title: Dup\n@end\n\n* B\n"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "target"), []byte("* Target\n"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "source"), []byte("* Source\n  [[Dup]]\n"), 0o644))
+		wikiInstance, err := local.NewLocalWiki(local.LocalWikiConfig{Root: root, Parse: local.PARSE_MODE_FULL})
+		require.NoError(t, err)
+
+		outDir := t.TempDir()
+		require.NoError(t, ExportHTML(wikiInstance, root, outDir))
+
+		// a/dup links to target, b/dup doesn't
+		page, err := os.ReadFile(filepath.Join(outDir, "a", "dup.html"))
+		require.NoError(t, err)
+		assert.Contains(t, string(page), `<a href="../target.html">target</a>`)
+		page, err = os.ReadFile(filepath.Join(outDir, "b", "dup.html"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(page), "target.html")
+
+		// backlinks follow the wiki, per node
+		for _, id := range []string{"a/dup", "b/dup"} {
+			backlinks, err := wikiInstance.GetBacklinks(id)
+			require.NoError(t, err)
+			page, err := os.ReadFile(filepath.Join(outDir, id+".html"))
+			require.NoError(t, err)
+			assert.Equal(t, len(backlinks) > 0, strings.Contains(string(page), `<a href="../source.html">source</a>`), id)
+		}
+
+		// the link from source goes to the node the wiki resolves the name to
+		dup, err := wikiInstance.GetNodeByName("Dup")
+		require.NoError(t, err)
+		page, err = os.ReadFile(filepath.Join(outDir, "source.html"))
+		require.NoError(t, err)
+		assert.Contains(t, string(page), `<a href="`+dup.GetID()+`.html">Dup</a>`)
+	})
+}
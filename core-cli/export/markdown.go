@@ -0,0 +1,133 @@
+package export
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corefs "github.com/3rd/core/core-lib/fs"
+	"github.com/3rd/core/core-lib/wiki"
+	"github.com/3rd/core/core-lib/wiki/markdown"
+)
+
+// ExportMarkdown writes the markdown of every node to outDir, keeping the directory layout of root
+func ExportMarkdown(wikiInstance wiki.Wiki, root string, outDir string, dialect markdown.Dialect) error {
+	nodes, err := wikiInstance.GetNodes()
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		path := filepath.Join(outDir, filepath.FromSlash(getNodeSlug(root, node))+".md")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		content := dialect.Apply(node.ToMarkdown(), node.GetMeta())
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type ImportOptions struct {
+	// Dialect reverts editor specific markdown before conversion
+	Dialect markdown.Dialect
+	// Overwrite replaces existing nodes instead of skipping them
+	Overwrite bool
+	// DryRun converts and reports without writing
+	DryRun bool
+}
+
+// ImportIssue is a markdown construct that was kept verbatim during import
+type ImportIssue struct {
+	Path  string
+	Issue markdown.Issue
+}
+
+func (i ImportIssue) String() string {
+	return fmt.Sprintf("%s:%s", i.Path, i.Issue)
+}
+
+const (
+	IMPORT_SKIP_EXISTING = "existing node"
+	IMPORT_SKIP_IGNORED  = "node name with a dot, ignored by the wiki"
+)
+
+// ImportSkip is a markdown file that wasn't imported
+type ImportSkip struct {
+	Path   string
+	Reason string
+}
+
+func (s ImportSkip) String() string {
+	return fmt.Sprintf("%s: %s", s.Path, s.Reason)
+}
+
+type ImportResult struct {
+	Imported []string
+	Skipped  []ImportSkip
+	Issues   []ImportIssue
+}
+
+// ImportMarkdown converts the markdown files of srcDir into syslang nodes under root,
+// hidden files and directories (e.g. .obsidian) are ignored
+func ImportMarkdown(srcDir string, root string, options ImportOptions) (*ImportResult, error) {
+	result := ImportResult{}
+
+	err := filepath.WalkDir(srcDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != srcDir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".md" {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(relativePath, ".md")
+		target := filepath.Join(root, name)
+		// names like v1.2 would be written but never loaded
+		if corefs.IsIgnored(name) {
+			result.Skipped = append(result.Skipped, ImportSkip{Path: path, Reason: IMPORT_SKIP_IGNORED})
+			return nil
+		}
+		if _, err := os.Stat(target); err == nil && !options.Overwrite {
+			result.Skipped = append(result.Skipped, ImportSkip{Path: target, Reason: IMPORT_SKIP_EXISTING})
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		syslang, issues := markdown.ToSyslang(options.Dialect.Revert(string(content)))
+		for _, issue := range issues {
+			result.Issues = append(result.Issues, ImportIssue{Path: path, Issue: issue})
+		}
+
+		if !options.DryRun {
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := corefs.WriteFileAtomic(target, []byte(syslang), 0o644); err != nil {
+				return err
+			}
+		}
+		result.Imported = append(result.Imported, target)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
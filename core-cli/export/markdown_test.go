@@ -0,0 +1,79 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/3rd/core/core-lib/wiki/local"
+	"github.com/3rd/core/core-lib/wiki/markdown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportMarkdown(t *testing.T) {
+	root, err := filepath.Abs("../../core-lib/test-data/wiki/export")
+	require.NoError(t, err)
+	wikiInstance, err := local.NewLocalWiki(local.LocalWikiConfig{Root: root, Parse: local.PARSE_MODE_FULL})
+	require.NoError(t, err)
+
+	outDir := t.TempDir()
+	require.NoError(t, ExportMarkdown(wikiInstance, root, outDir, markdown.Dialect{Frontmatter: true}))
+
+	t.Run("Keep directory layout", func(t *testing.T) {
+		assert.FileExists(t, filepath.Join(outDir, "project-a.md"))
+		assert.FileExists(t, filepath.Join(outDir, "notes", "note-1.md"))
+	})
+
+	t.Run("Apply dialect", func(t *testing.T) {
+		content, err := os.ReadFile(filepath.Join(outDir, "notes", "note-1.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "---\ntype: note\n---\n")
+	})
+}
+
+func TestImportMarkdown(t *testing.T) {
+	vault := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(vault, ".obsidian"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(vault, ".obsidian", "app.md"), []byte("# hidden\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(vault, "projects"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(vault, "projects", "a.md"), []byte("# A\n- [/] task\n| a | b |\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(vault, "existing.md"), []byte("# new\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(vault, "image.png"), []byte{}, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(vault, "v1.2.md"), []byte("# v1.2\n"), 0o644))
+
+	t.Run("Convert and report issues", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "existing"), []byte("* old\n"), 0o644))
+
+		result, err := ImportMarkdown(vault, root, ImportOptions{
+			Dialect: markdown.Dialect{CheckboxStyle: markdown.CHECKBOX_STYLE_OBSIDIAN},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(root, "projects", "a")}, result.Imported)
+		assert.Equal(t, []ImportSkip{
+			{Path: filepath.Join(root, "existing"), Reason: IMPORT_SKIP_EXISTING},
+			{Path: filepath.Join(vault, "v1.2.md"), Reason: IMPORT_SKIP_IGNORED},
+		}, result.Skipped)
+		assert.NoFileExists(t, filepath.Join(root, "v1.2"))
+		require.Len(t, result.Issues, 1)
+		assert.Equal(t, "table", result.Issues[0].Issue.Construct)
+
+		content, err := os.ReadFile(filepath.Join(root, "projects", "a"))
+		require.NoError(t, err)
+		assert.Equal(t, "* A\n  [-] task\n  | a | b |\n", string(content))
+		existing, err := os.ReadFile(filepath.Join(root, "existing"))
+		require.NoError(t, err)
+		assert.Equal(t, "* old\n", string(existing))
+	})
+
+	t.Run("Dry run writes nothing", func(t *testing.T) {
+		root := t.TempDir()
+		result, err := ImportMarkdown(vault, root, ImportOptions{DryRun: true, Overwrite: true})
+		require.NoError(t, err)
+		assert.Len(t, result.Imported, 2)
+		entries, err := os.ReadDir(root)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,337 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/3rd/core/core-lib/wiki"
+)
+
+type ChangeEvent struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+}
+
+// REQUEST_HEADER marks mutations sent by API clients, browsers can't add it to cross-origin simple requests
+const REQUEST_HEADER = "X-Core-Request"
+
+type Server struct {
+	wiki        wiki.Wiki
+	addr        string
+	mutex       sync.RWMutex
+	subscribers map[chan ChangeEvent]struct{}
+	subMutex    sync.Mutex
+}
+
+// NewServer creates a server for the given listen address, requests for other hosts are rejected.
+func NewServer(wikiInstance wiki.Wiki, addr string) *Server {
+	return &Server{
+		wiki:        wikiInstance,
+		addr:        addr,
+		subscribers: map[chan ChangeEvent]struct{}{},
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/nodes", s.handleListNodes)
+	mux.HandleFunc("GET /api/nodes/{id}", s.handleGetNode)
+	mux.HandleFunc("GET /api/nodes/{id}/content", s.handleGetNodeContent)
+	mux.HandleFunc("GET /api/nodes/{id}/markdown", s.handleGetNodeMarkdown)
+	mux.HandleFunc("GET /api/tasks", s.handleListTasks)
+	mux.HandleFunc("POST /api/nodes/{id}/tasks/{line}/start", s.handleTaskMutation(mutationStart))
+	mux.HandleFunc("POST /api/nodes/{id}/tasks/{line}/stop", s.handleTaskMutation(mutationStop))
+	mux.HandleFunc("POST /api/nodes/{id}/tasks/{line}/done", s.handleTaskMutation(mutationDone))
+	mux.HandleFunc("GET /api/events", s.handleEvents)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.isAllowedHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host not allowed: %s", r.Host))
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && !isAPIRequest(r) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("mutations require the %s header or a JSON body", REQUEST_HEADER))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// isAllowedHost guards against DNS rebinding, the Host must be the listen address, localhost or an IP on the listen port
+func (s *Server) isAllowedHost(host string) bool {
+	if host == s.addr {
+		return true
+	}
+	listenHost, listenPort, err := net.SplitHostPort(s.addr)
+	if err != nil {
+		return false
+	}
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil || port != listenPort {
+		return false
+	}
+	return hostname == listenHost || hostname == "localhost" || net.ParseIP(hostname) != nil
+}
+
+// isAPIRequest reports whether the request could only have been sent by a non-browser client or a same-origin page
+func isAPIRequest(r *http.Request) bool {
+	if r.Header.Get(REQUEST_HEADER) != "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// Reload re-reads the wiki and notifies event subscribers, call it from the file watcher.
+func (s *Server) Reload(event ChangeEvent) error {
+	s.mutex.Lock()
+	err := s.wiki.Reload()
+	s.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.subMutex.Lock()
+	defer s.subMutex.Unlock()
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			// slow client, drop the event rather than block the watcher
+		}
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func (s *Server) getNode(w http.ResponseWriter, r *http.Request) wiki.Node {
+	id := r.PathValue("id")
+	node, err := s.wiki.GetNode(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return nil
+	}
+	if node == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("node not found: %s", id))
+		return nil
+	}
+	return node
+}
+
+func (s *Server) handleListNodes(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	nodeType := r.URL.Query().Get("type")
+	nodes, err := s.wiki.GetNodes()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := []wiki.JSONNode{}
+	for _, node := range nodes {
+		if nodeType != "" && node.GetMeta()["type"] != nodeType {
+			continue
+		}
+		result = append(result, wiki.NewJSONNode(node))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleGetNode(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	node := s.getNode(w, r)
+	if node == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, wiki.NewJSONNode(node))
+}
+
+func (s *Server) handleGetNodeContent(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	node := s.getNode(w, r)
+	if node == nil {
+		return
+	}
+	content, err := node.GetContent()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(content))
+}
+
+func (s *Server) handleGetNodeMarkdown(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	node := s.getNode(w, r)
+	if node == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Write([]byte(node.ToMarkdown()))
+}
+
+// handleListTasks supports the status, node, type and inProgress query filters
+func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	query := r.URL.Query()
+	status := query.Get("status")
+	nodeID := query.Get("node")
+	nodeType := query.Get("type")
+	inProgress := query.Get("inProgress") == "true"
+
+	nodes, err := s.wiki.GetNodes()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := []wiki.JSONTask{}
+	for _, node := range nodes {
+		if nodeID != "" && node.GetID() != nodeID {
+			continue
+		}
+		if nodeType != "" && node.GetMeta()["type"] != nodeType {
+			continue
+		}
+		for _, task := range node.GetTasks() {
+			if status != "" && string(task.Status) != status {
+				continue
+			}
+			if inProgress && !task.IsInProgress() {
+				continue
+			}
+			result = append(result, wiki.NewJSONTask(task))
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+type taskMutation func(wikiInstance wiki.Wiki, task *wiki.Task, now time.Time) error
+
+func mutationStart(wikiInstance wiki.Wiki, task *wiki.Task, now time.Time) error {
+	if task.IsInProgress() {
+		return fmt.Errorf("task already in progress")
+	}
+	return wikiInstance.StartTaskSession(task, now)
+}
+
+func mutationStop(wikiInstance wiki.Wiki, task *wiki.Task, now time.Time) error {
+	if !task.IsInProgress() {
+		return fmt.Errorf("task not in progress")
+	}
+	return wikiInstance.StopTaskSession(task, now)
+}
+
+func mutationDone(wikiInstance wiki.Wiki, task *wiki.Task, now time.Time) error {
+	return wikiInstance.ToggleTaskDone(task, now)
+}
+
+func (s *Server) handleTaskMutation(mutation taskMutation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		lineNumber, err := strconv.ParseUint(r.PathValue("line"), 10, 32)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		node := s.getNode(w, r)
+		if node == nil {
+			return
+		}
+
+		var target *wiki.Task
+		for _, task := range node.GetTasks() {
+			if task.LineNumber == uint32(lineNumber) {
+				target = task
+				break
+			}
+		}
+		if target == nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("no task at %s:%d", node.GetID(), lineNumber))
+			return
+		}
+
+		if err := mutation(s.wiki, target, time.Now()); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+
+		// line numbers are stable for the mutated task, its own line never moves
+		node, _ = s.wiki.GetNode(node.GetID())
+		if node != nil {
+			for _, task := range node.GetTasks() {
+				if task.LineNumber == uint32(lineNumber) {
+					writeJSON(w, http.StatusOK, wiki.NewJSONTask(task))
+					return
+				}
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming unsupported"))
+		return
+	}
+
+	events := make(chan ChangeEvent, 16)
+	s.subMutex.Lock()
+	s.subscribers[events] = struct{}{}
+	s.subMutex.Unlock()
+	defer func() {
+		s.subMutex.Lock()
+		delete(s.subscribers, events)
+		s.subMutex.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: change\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/3rd/core/core-lib/wiki"
+	"github.com/3rd/core/core-lib/wiki/local"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, root string) *httptest.Server {
+	wikiInstance, err := local.NewLocalWiki(local.LocalWikiConfig{
+		Root:  root,
+		Parse: local.PARSE_MODE_FULL,
+	})
+	require.NoError(t, err)
+
+	return startTestServer(t, wikiInstance)
+}
+
+// startTestServer serves the wiki on a random port, the server only accepts its own listen address
+func startTestServer(t *testing.T, wikiInstance wiki.Wiki) *httptest.Server {
+	ts := httptest.NewUnstartedServer(nil)
+	ts.Config.Handler = NewServer(wikiInstance, ts.Listener.Addr().String()).Handler()
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func postMutation(t *testing.T, url string) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	require.NoError(t, err)
+	req.Header.Set(REQUEST_HEADER, "1")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return res
+}
+
+func getJSON(t *testing.T, url string, target any) int {
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(res.Body).Decode(target))
+	}
+	return res.StatusCode
+}
+
+func TestServer(t *testing.T) {
+	defaultRoot, err := filepath.Abs("../../core-lib/test-data/wiki/default")
+	require.NoError(t, err)
+	tasksRoot, err := filepath.Abs("../../core-lib/test-data/wiki/tasks")
+	require.NoError(t, err)
+
+	t.Run("List nodes", func(t *testing.T) {
+		ts := newTestServer(t, defaultRoot)
+
+		nodes := []wiki.JSONNode{}
+		status := getJSON(t, ts.URL+"/api/nodes", &nodes)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, nodes, 4)
+	})
+
+	t.Run("Get node", func(t *testing.T) {
+		ts := newTestServer(t, defaultRoot)
+
+		node := wiki.JSONNode{}
+		status := getJSON(t, ts.URL+"/api/nodes/root-2", &node)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "Custom title", node.Name)
+		assert.Equal(t, filepath.Join(defaultRoot, "root-2"), node.Path)
+
+		// ids of nested nodes are root-relative paths
+		status = getJSON(t, ts.URL+"/api/nodes/nested%2Fnested-1", &node)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, filepath.Join(defaultRoot, "nested", "nested-1"), node.Path)
+
+		status = getJSON(t, ts.URL+"/api/nodes/missing", &node)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("Get node content", func(t *testing.T) {
+		ts := newTestServer(t, defaultRoot)
+
+		res, err := http.Get(ts.URL + "/api/nodes/root-1/content")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "This is the root-1 node.\n", string(body))
+	})
+
+	t.Run("List tasks with filters", func(t *testing.T) {
+		ts := newTestServer(t, tasksRoot)
+
+		tasks := []wiki.JSONTask{}
+		getJSON(t, ts.URL+"/api/tasks", &tasks)
+		assert.Len(t, tasks, 4)
+
+		tasks = []wiki.JSONTask{}
+		getJSON(t, ts.URL+"/api/tasks?status=active", &tasks)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "task 2", tasks[0].Text)
+	})
+
+	t.Run("Start a task", func(t *testing.T) {
+		root := t.TempDir()
+		content, err := os.ReadFile(filepath.Join(tasksRoot, "sample"))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(root, "sample"), content, 0o644))
+		ts := newTestServer(t, root)
+
+		res := postMutation(t, ts.URL+"/api/nodes/sample/tasks/2/start")
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		task := wiki.JSONTask{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&task))
+		assert.True(t, task.InProgress)
+
+		res = postMutation(t, ts.URL+"/api/nodes/sample/tasks/99/start")
+		res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("Stop a task that isn't in progress", func(t *testing.T) {
+		root := t.TempDir()
+		content, err := os.ReadFile(filepath.Join(tasksRoot, "sample"))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(root, "sample"), content, 0o644))
+		ts := newTestServer(t, root)
+
+		res := postMutation(t, ts.URL+"/api/nodes/sample/tasks/2/stop")
+		res.Body.Close()
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+		unchanged, err := os.ReadFile(filepath.Join(root, "sample"))
+		require.NoError(t, err)
+		assert.Equal(t, string(content), string(unchanged))
+	})
+
+	t.Run("Reject simple cross-origin mutations", func(t *testing.T) {
+		root := t.TempDir()
+		content, err := os.ReadFile(filepath.Join(tasksRoot, "sample"))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(root, "sample"), content, 0o644))
+		ts := newTestServer(t, root)
+
+		// an empty body or a form content type is what a no-cors fetch from any page can send
+		for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+			res, err := http.Post(ts.URL+"/api/nodes/sample/tasks/2/start", contentType, nil)
+			require.NoError(t, err)
+			res.Body.Close()
+			assert.Equal(t, http.StatusForbidden, res.StatusCode, contentType)
+		}
+		unchanged, err := os.ReadFile(filepath.Join(root, "sample"))
+		require.NoError(t, err)
+		assert.Equal(t, string(content), string(unchanged))
+
+		res, err := http.Post(ts.URL+"/api/nodes/sample/tasks/2/start", "application/json", strings.NewReader("{}"))
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("Reject other hosts", func(t *testing.T) {
+		ts := newTestServer(t, defaultRoot)
+		port := ts.Listener.Addr().(*net.TCPAddr).Port
+
+		for host, expected := range map[string]int{
+			ts.Listener.Addr().String():          http.StatusOK,
+			fmt.Sprintf("localhost:%d", port):    http.StatusOK,
+			fmt.Sprintf("evil.example:%d", port): http.StatusForbidden,
+			"127.0.0.1:1":                        http.StatusForbidden,
+			"evil.example":                       http.StatusForbidden,
+		} {
+			req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/nodes", nil)
+			require.NoError(t, err)
+			req.Host = host
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			res.Body.Close()
+			assert.Equal(t, expected, res.StatusCode, host)
+		}
+	})
+
+	t.Run("Stream change events", func(t *testing.T) {
+		wikiInstance, err := local.NewLocalWiki(local.LocalWikiConfig{Root: defaultRoot, Parse: local.PARSE_MODE_FULL})
+		require.NoError(t, err)
+		ts := httptest.NewUnstartedServer(nil)
+		apiServer := NewServer(wikiInstance, ts.Listener.Addr().String())
+		ts.Config.Handler = apiServer.Handler()
+		ts.Start()
+		defer ts.Close()
+
+		res, err := http.Get(ts.URL + "/api/events")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+		// the subscription is registered before the headers are flushed
+		require.NoError(t, apiServer.Reload(ChangeEvent{Op: "WRITE", Path: "root-1"}))
+
+		reader := bufio.NewReader(res.Body)
+		lines := make(chan string)
+		go func() {
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					close(lines)
+					return
+				}
+				lines <- line
+			}
+		}()
+
+		select {
+		case line := <-lines:
+			assert.Equal(t, "event: change\n", line)
+			line = <-lines
+			assert.True(t, strings.HasPrefix(line, `data: {"op":"WRITE","path":"root-1"}`))
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	})
+}
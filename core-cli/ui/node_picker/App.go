@@ -0,0 +1,139 @@
+package nodepicker
+
+import (
+	"core/ui/task_interactive/theme"
+	"fmt"
+
+	ui "github.com/3rd/go-futui"
+	"github.com/gdamore/tcell/v2"
+)
+
+type Item struct {
+	Label  string
+	Detail string
+	Value  string
+}
+
+type Providers struct {
+	// GetItems returns the ranked items for a query
+	GetItems func(query string) []Item
+}
+
+type App struct {
+	ui.App
+	providers     Providers
+	query         string
+	items         []Item
+	selectedIndex int
+	scrollOffset  int
+	result        *Item
+}
+
+func (app *App) Setup() {
+	app.filter()
+}
+
+func (app *App) filter() {
+	app.items = app.providers.GetItems(app.query)
+	app.selectedIndex = 0
+	app.scrollOffset = 0
+}
+
+func (app *App) getListHeight() int {
+	// prompt and help lines
+	return max(app.Height()-2, 0)
+}
+
+func (app *App) move(delta int) {
+	if len(app.items) == 0 {
+		return
+	}
+	app.selectedIndex = min(max(app.selectedIndex+delta, 0), len(app.items)-1)
+	if app.selectedIndex < app.scrollOffset {
+		app.scrollOffset = app.selectedIndex
+	}
+	if height := app.getListHeight(); height > 0 && app.selectedIndex >= app.scrollOffset+height {
+		app.scrollOffset = app.selectedIndex - height + 1
+	}
+}
+
+func (app *App) OnKeypress(ev tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyCtrlC, tcell.KeyEscape:
+		app.Quit()
+		return
+	case tcell.KeyEnter:
+		if len(app.items) > 0 {
+			app.result = &app.items[app.selectedIndex]
+		}
+		app.Quit()
+		return
+	case tcell.KeyDown, tcell.KeyCtrlN, tcell.KeyCtrlJ:
+		app.move(1)
+	case tcell.KeyUp, tcell.KeyCtrlP, tcell.KeyCtrlK:
+		app.move(-1)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(app.query) > 0 {
+			runes := []rune(app.query)
+			app.query = string(runes[:len(runes)-1])
+			app.filter()
+		}
+	case tcell.KeyCtrlU:
+		app.query = ""
+		app.filter()
+	case tcell.KeyRune:
+		app.query += string(ev.Rune())
+		app.filter()
+	}
+	app.Update()
+}
+
+func (app *App) OnResize() {
+	app.Update()
+}
+
+func (app *App) Render() ui.Buffer {
+	b := ui.Buffer{}
+	b.Resize(app.Width(), app.Height())
+	b.FillStyle(theme.APP_STYLE)
+
+	// prompt
+	prompt := fmt.Sprintf("> %s", app.query)
+	b.Text(0, 0, prompt, theme.MODAL_TITLE_STYLE)
+	count := fmt.Sprintf("%d", len(app.items))
+	b.Text(max(app.Width()-len(count)-1, len(prompt)+1), 0, count, theme.MODAL_HELP_STYLE)
+
+	// items
+	height := app.getListHeight()
+	for i := app.scrollOffset; i < len(app.items) && i-app.scrollOffset < height; i++ {
+		item := app.items[i]
+		y := 1 + i - app.scrollOffset
+		isSelected := i == app.selectedIndex
+
+		lineStyle := theme.ModalProjectLineStyle(true, isSelected)
+		lineBuffer := ui.Buffer{}
+		lineBuffer.Resize(app.Width(), 1)
+		lineBuffer.FillStyle(lineStyle)
+		b.DrawBuffer(0, y, lineBuffer)
+
+		b.Text(1, y, item.Label, lineStyle)
+		if item.Detail != "" {
+			b.Text(len([]rune(item.Label))+2, y, item.Detail, theme.ModalProjectLineStyle(false, isSelected))
+		}
+	}
+
+	// help
+	b.Text(0, app.Height()-1, "type to filter | up/down: move | enter: select | esc: cancel", theme.MODAL_HELP_STYLE)
+
+	return b
+}
+
+// Run shows the picker and returns the selected item, or nil when cancelled
+func Run(providers Providers, query string) *Item {
+	app := App{
+		providers: providers,
+		query:     query,
+	}
+	app.Run(&app)
+	return app.result
+}
@@ -14,25 +14,12 @@ import (
 	"time"
 
 	"github.com/3rd/core/core-lib/wiki"
-	localWiki "github.com/3rd/core/core-lib/wiki/local"
 	ui "github.com/3rd/go-futui"
 	"github.com/atotto/clipboard"
 	"github.com/gdamore/tcell/v2"
 	"github.com/radovskyb/watcher"
 )
 
-const INDENT = "  "
-
-func getIndentLevel(task *wiki.Task) int {
-	indentLevel := 0
-	lineText := task.LineText
-	for strings.HasPrefix(lineText, INDENT) {
-		indentLevel++
-		lineText = lineText[len(INDENT):]
-	}
-	return indentLevel
-}
-
 type GetTasksResult struct {
 	Nodes                      []wiki.Node
 	Tasks                      []*wiki.Task
@@ -44,6 +31,7 @@ type GetTasksResult struct {
 type Providers struct {
 	GetRoot  func() string
 	GetTasks func() GetTasksResult
+	GetWiki  func() wiki.Wiki
 }
 
 type App struct {
@@ -387,7 +375,7 @@ func (app *App) handleToggleHideDone() {
 
 func (app *App) handleActiveEdit() {
 	task := app.state.FilteredTasks[app.state.ActiveSelectedIndex]
-	node := task.Node.(*localWiki.LocalNode)
+	node := task.Node
 	if node == nil {
 		return
 	}
@@ -410,70 +398,15 @@ func (app *App) handleActiveEdit() {
 
 func (app *App) handleActiveToggleInProgress() {
 	task := app.state.FilteredTasks[app.state.ActiveSelectedIndex]
-	node := task.Node.(*localWiki.LocalNode)
+	wikiInstance := app.providers.GetWiki()
 	now := time.Now()
-	text, err := node.Text()
-	if err != nil {
-		panic(err)
-	}
-	lines := strings.Split(text, "\n")
 
+	var err error
 	if task.IsInProgress() {
-		// end current session
-		lastWorkSession := task.GetLastSession()
-		st := fmt.Sprintf("Session: %04d.%02d.%02d %02d:%02d-%02d:%02d", lastWorkSession.Start.Year(), lastWorkSession.Start.Month(), lastWorkSession.Start.Day(), lastWorkSession.Start.Hour(), lastWorkSession.Start.Minute(), now.Hour(), now.Minute())
-		for i := 0; i <= getIndentLevel(task); i++ {
-			st = INDENT + st
-		}
-
-		deletePreviousSession := false
-		var previousSession *wiki.TaskSession
-		for _, session := range task.Sessions {
-			if session.End == nil {
-				break
-			}
-			previousSession = &session
-		}
-		if previousSession != nil && previousSession.End != nil &&
-			previousSession.Start.Year() == now.Year() &&
-			previousSession.Start.Month() == now.Month() &&
-			previousSession.Start.Day() == now.Day() &&
-			previousSession.Start.Hour() == now.Hour() &&
-			previousSession.Start.Minute() == now.Minute() {
-			deletePreviousSession = true
-		}
-
-		lines[lastWorkSession.LineNumber] = st
-		if deletePreviousSession {
-			lines = append(lines[:previousSession.LineNumber], lines[previousSession.LineNumber+1:]...)
-		}
+		err = wikiInstance.StopTaskSession(task, now)
 	} else {
-		// create new session
-		st := fmt.Sprintf("Session: %04d.%02d.%02d %02d:%02d", now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute())
-		for i := 0; i <= getIndentLevel(task); i++ {
-			st = INDENT + st
-		}
-
-		i := task.LineNumber + 1
-		lastWorkSession := task.GetLastSession()
-		if lastWorkSession != nil {
-			i = lastWorkSession.LineNumber + 1
-		} else if task.Schedule != nil {
-			i = task.Schedule.LineNumber + 1
-		}
-
-		lines = append(lines, "")
-		copy(lines[i+1:], lines[i:])
-		lines[i] = st
-	}
-
-	out, err := os.Create(node.GetPath())
-	if err != nil {
-		panic(err)
+		err = wikiInstance.StartTaskSession(task, now)
 	}
-	defer out.Close()
-
-	_, err = out.WriteString(strings.Join(lines, "\n"))
 	if err != nil {
 		panic(err)
 	}
@@ -484,109 +417,9 @@ func (app *App) handleActiveToggleInProgress() {
 
 func (app *App) handleActiveToggleDone() {
 	task := app.state.FilteredTasks[app.state.ActiveSelectedIndex]
-	node := task.Node.(*localWiki.LocalNode)
-	now := time.Now()
-	text, err := node.Text()
-	if err != nil {
-		panic(err)
-	}
-	lines := strings.Split(text, "\n")
-
-	// recurring tasks
-	if task.Schedule != nil && task.Schedule.Repeat != "" {
-		completion := task.GetCompletionForDate(time.Now())
-
-		// remove completion
-		if completion != nil {
-			lines = append(lines[:completion.LineNumber], lines[completion.LineNumber+1:]...)
-		} else {
-			// add completion
-			now := time.Now()
-			st := fmt.Sprintf("Done: %04d.%02d.%02d %02d:%02d", now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute())
-			for i := 0; i <= getIndentLevel(task); i++ {
-				st = INDENT + st
-			}
-			i := task.Schedule.LineNumber + 1
-			lastCompletion := task.GetLastCompletion()
-			if lastCompletion != nil {
-				i = lastCompletion.LineNumber + 1
-			} else {
-				lastSession := task.GetLastSession()
-				if lastSession != nil {
-					i = lastSession.LineNumber + 1
-				}
-			}
-			lines = append(lines, "")
-			copy(lines[i+1:], lines[i:])
-			lines[i] = st
-
-			// session in-progress task -> end current session
-			if task.IsInProgress() {
-				now := time.Now()
-				// end current session
-				last := *task.GetLastSession()
-				sessionText := fmt.Sprintf("Session: %04d.%02d.%02d %02d:%02d-%02d:%02d", last.Start.Year(), last.Start.Month(), last.Start.Day(), last.Start.Hour(), last.Start.Minute(), now.Hour(), now.Minute())
-				for i := 0; i <= getIndentLevel(task); i++ {
-					sessionText = INDENT + sessionText
-				}
-				lines[last.LineNumber] = sessionText
-			}
-		}
-	} else {
-		// non-recurring tasks
-
-		// marker (no schedule): [x] -> [-] or [-] -> [x]
-		if task.Schedule == nil {
-			if task.Status == wiki.TASK_STATUS_DONE {
-				lines[task.LineNumber] = strings.Replace(lines[task.LineNumber], "[x]", "[-]", 1)
-			} else {
-				lines[task.LineNumber] = strings.Replace(lines[task.LineNumber], "[-]", "[x]", 1)
-			}
-		}
-
-		// marker (scheduled): [x | -] <-> [ ]
-		if task.Schedule != nil {
-			switch task.Status {
-			case wiki.TASK_STATUS_DONE:
-				lines[task.LineNumber] = strings.Replace(lines[task.LineNumber], "[x]", "[ ]", 1)
-			case wiki.TASK_STATUS_DEFAULT:
-				lines[task.LineNumber] = strings.Replace(lines[task.LineNumber], "[ ]", "[x]", 1)
-			case wiki.TASK_STATUS_ACTIVE:
-				lines[task.LineNumber] = strings.Replace(lines[task.LineNumber], "[-]", "[x]", 1)
-			}
-		}
-
-		// current task
-		if task.IsInProgress() {
-			// end current session
-			lastWorkSession := task.GetLastSession()
-			sessionText := fmt.Sprintf("Session: %04d.%02d.%02d %02d:%02d-%02d:%02d", lastWorkSession.Start.Year(), lastWorkSession.Start.Month(), lastWorkSession.Start.Day(), lastWorkSession.Start.Hour(), lastWorkSession.Start.Minute(), now.Hour(), now.Minute())
-			for i := 0; i <= getIndentLevel(task); i++ {
-				sessionText = INDENT + sessionText
-			}
-			lines[lastWorkSession.LineNumber] = sessionText
-		}
-
-		// inactive task -> insert empty work session
-		if !task.IsInProgress() && len(task.Sessions) == 0 && task.Status != wiki.TASK_STATUS_DONE {
-			now := time.Now()
-			st := fmt.Sprintf("Session: %04d.%02d.%02d %02d:%02d-%02d:%02d", now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Hour(), now.Minute())
-			for i := 0; i <= getIndentLevel(task); i++ {
-				st = INDENT + st
-			}
-			i := task.LineNumber + 1
-			lines = append(lines, "")
-			copy(lines[i+1:], lines[i:])
-			lines[i] = st
-		}
-	}
-
-	out, err := os.Create(node.GetPath())
-	if err != nil {
+	if err := app.providers.GetWiki().ToggleTaskDone(task, time.Now()); err != nil {
 		panic(err)
 	}
-	defer out.Close()
-	out.WriteString(strings.Join(lines, "\n"))
 
 	app.loadTasks()
 	app.Update()
@@ -598,19 +431,15 @@ func (app *App) handleActiveDeactivateTask() {
 	}
 
 	task := app.state.FilteredTasks[app.state.ActiveSelectedIndex]
-	node := task.Node.(*localWiki.LocalNode)
-	text, _ := node.Text()
+	text, _ := task.Node.GetContent()
 	lines := strings.Split(string(text), "\n")
 
 	updatedLineText := strings.Replace(task.LineText, "[-]", "[ ]", 1)
 	lines[task.LineNumber] = updatedLineText
 
-	out, err := os.Create(node.GetPath())
-	if err != nil {
+	if err := app.providers.GetWiki().UpdateNode(task.Node.GetID(), strings.Join(lines, "\n")); err != nil {
 		panic(err)
 	}
-	defer out.Close()
-	out.WriteString(strings.Join(lines, "\n"))
 
 	app.Update()
 }
@@ -681,7 +510,7 @@ func (app *App) handleProjectsEdit() {
 	app.state.ActiveMode = state.APP_ACTIVE_MODE_EDITOR
 
 	app.Screen.Suspend()
-	editorArgs = append(editorArgs, project.(*localWiki.LocalNode).GetPath())
+	editorArgs = append(editorArgs, project.GetPath())
 	editorArgs = append(editorArgs, "+norm zz")
 	cmd := exec.Command("nvim", editorArgs...)
 	cmd.Stdin = os.Stdin
@@ -742,8 +571,11 @@ func (app *App) handleProjectsToggleTask() {
 	if task == nil {
 		return
 	}
-	node := task.Node.(*localWiki.LocalNode)
-	text, _ := node.Text()
+	text, err := task.Node.GetContent()
+	if err != nil {
+		app.showNotification(fmt.Sprintf("Failed to read task: %v", err))
+		return
+	}
 	lines := strings.Split(string(text), "\n")
 
 	if task.Status == wiki.TASK_STATUS_ACTIVE {
@@ -752,12 +584,9 @@ func (app *App) handleProjectsToggleTask() {
 		lines[task.LineNumber] = strings.Replace(lines[task.LineNumber], "[ ]", "[-]", 1)
 	}
 
-	out, err := os.Create(node.GetPath())
-	if err != nil {
-		return
+	if err := app.providers.GetWiki().UpdateNode(task.Node.GetID(), strings.Join(lines, "\n")); err != nil {
+		app.showNotification(fmt.Sprintf("Failed to update task: %v", err))
 	}
-	defer out.Close()
-	out.WriteString(strings.Join(lines, "\n"))
 }
 
 func (app *App) handleNavigateTop() {
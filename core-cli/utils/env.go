@@ -9,6 +9,8 @@ import (
 type Env struct {
 	WIKI_ROOT string
 	TASK_ROOT string
+	// unix socket used by `core daemon`, defaults to $XDG_RUNTIME_DIR/core.sock
+	CORE_SOCKET string
 }
 
 func GetEnv() Env {
@@ -19,5 +21,8 @@ func GetEnv() Env {
 	if val, ok := os.LookupEnv("TASK_ROOT"); ok {
 		env.TASK_ROOT = val
 	}
+	if val, ok := os.LookupEnv("CORE_SOCKET"); ok {
+		env.CORE_SOCKET = val
+	}
 	return env
 }
@@ -1,6 +1,10 @@
 package utils
 
-import "github.com/3rd/core/core-lib/wiki"
+import (
+	"time"
+
+	"github.com/3rd/core/core-lib/wiki"
+)
 
 func ComputeTaskReward(task *wiki.Task) int {
 	points := task.Priority
@@ -12,3 +16,200 @@ func ComputeTaskReward(task *wiki.Task) int {
 	}
 	return int(points)
 }
+
+// IsTaskNode matches the node types whose tasks are tracked
+var IsTaskNode = wiki.MetaIn("type", "project", "person")
+
+type CurrentTask struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	Node          wiki.JSONNode `json:"node"`
+	Task          wiki.JSONTask `json:"task"`
+	SessionStart  time.Time     `json:"sessionStart"`
+	Elapsed       int64         `json:"elapsed"`
+	TodayTotal    int64         `json:"todayTotal"`
+}
+
+type ActiveTasks struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	GeneratedAt   string          `json:"generatedAt"`
+	ActiveTasks   []wiki.JSONTask `json:"activeTasks"`
+	DoneTasks     []wiki.JSONTask `json:"doneTasks,omitempty"`
+}
+
+type Report struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	GeneratedAt   string        `json:"generatedAt"`
+	Entries       []ReportEntry `json:"entries"`
+}
+
+type ReportEntry struct {
+	NodeID   string `json:"nodeId"`
+	NodeName string `json:"nodeName"`
+	Text     string `json:"text"`
+	Duration int64  `json:"duration"`
+}
+
+func getSessionStart(task *wiki.Task, now time.Time) time.Time {
+	lastSession := task.GetLastSession()
+	if lastSession != nil && lastSession.End == nil {
+		return lastSession.Start
+	}
+	if task.Schedule != nil {
+		return task.Schedule.Start
+	}
+	return now
+}
+
+// FindCurrentTask returns the first in-progress task of a task node
+func FindCurrentTask[T wiki.Node](nodes []T) *wiki.Task {
+	for _, node := range nodes {
+		if !IsTaskNode(node) {
+			continue
+		}
+		for _, task := range node.GetTasks() {
+			if task.IsInProgress() {
+				return task
+			}
+		}
+	}
+	return nil
+}
+
+// FindOpenSessionTask returns the first task of a task node with an open work session,
+// tasks only in progress because of their schedule have nothing to stop
+func FindOpenSessionTask[T wiki.Node](nodes []T) *wiki.Task {
+	for _, node := range nodes {
+		if !IsTaskNode(node) {
+			continue
+		}
+		for _, task := range node.GetTasks() {
+			if task.HasOpenSession() {
+				return task
+			}
+		}
+	}
+	return nil
+}
+
+func NewCurrentTask(task *wiki.Task, now time.Time) CurrentTask {
+	sessionStart := getSessionStart(task, now)
+	return CurrentTask{
+		SchemaVersion: wiki.JSON_SCHEMA_VERSION,
+		Node:          wiki.NewJSONNode(task.Node),
+		Task:          wiki.NewJSONTask(task),
+		SessionStart:  sessionStart,
+		Elapsed:       int64(now.Sub(sessionStart).Round(time.Second).Seconds()),
+		TodayTotal:    int64(task.GetTotalSessionTimeForDate(now).Round(time.Second).Seconds()),
+	}
+}
+
+// FindActiveTasks returns the active tasks, and the tasks done today if includeDone is set
+func FindActiveTasks[T wiki.Node](nodes []T, includeDone bool, now time.Time) (active []*wiki.Task, done []*wiki.Task) {
+	active = []*wiki.Task{}
+	done = []*wiki.Task{}
+
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local)
+
+	for _, node := range nodes {
+		if !IsTaskNode(node) {
+			continue
+		}
+		for _, task := range node.GetTasks() {
+			if task.Status == wiki.TASK_STATUS_ACTIVE {
+				active = append(active, task)
+			}
+			if includeDone && task.Status == wiki.TASK_STATUS_DONE {
+				for _, session := range task.Sessions {
+					if session.Start.After(startOfDay) && session.Start.Before(endOfDay) {
+						done = append(done, task)
+						break
+					}
+				}
+			}
+		}
+	}
+	return active, done
+}
+
+func NewActiveTasks(active []*wiki.Task, done []*wiki.Task, now time.Time) ActiveTasks {
+	result := ActiveTasks{
+		SchemaVersion: wiki.JSON_SCHEMA_VERSION,
+		GeneratedAt:   now.Format(time.RFC3339),
+		ActiveTasks:   []wiki.JSONTask{},
+	}
+	for _, task := range active {
+		result.ActiveTasks = append(result.ActiveTasks, wiki.NewJSONTask(task))
+	}
+	for _, task := range done {
+		result.DoneTasks = append(result.DoneTasks, wiki.NewJSONTask(task))
+	}
+	return result
+}
+
+// GetReport sums the session time spent on each task on the given day
+func GetReport[T wiki.Node](nodes []T, date time.Time) []ReportEntry {
+	entries := []ReportEntry{}
+	for _, node := range nodes {
+		if !IsTaskNode(node) {
+			continue
+		}
+		for _, task := range node.GetTasks() {
+			duration := task.GetTotalSessionTimeForDate(date)
+			if duration == 0 {
+				continue
+			}
+			entries = append(entries, ReportEntry{
+				NodeID:   node.GetID(),
+				NodeName: node.GetName(),
+				Text:     task.Text,
+				Duration: int64(duration.Round(time.Second).Seconds()),
+			})
+		}
+	}
+	return entries
+}
+
+func NewReport(entries []ReportEntry, now time.Time) Report {
+	return Report{
+		SchemaVersion: wiki.JSON_SCHEMA_VERSION,
+		GeneratedAt:   now.Format(time.RFC3339),
+		Entries:       entries,
+	}
+}
+
+// FindTodayTasks returns the tasks worked on, scheduled or completed on the given day
+func FindTodayTasks[T wiki.Node](nodes []T, now time.Time) []*wiki.Task {
+	tasks := []*wiki.Task{}
+
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endOfDay := startOfDay.AddDate(0, 0, 1)
+	isToday := func(t time.Time) bool {
+		return !t.Before(startOfDay) && t.Before(endOfDay)
+	}
+
+	for _, node := range nodes {
+		if !IsTaskNode(node) {
+			continue
+		}
+		for _, task := range node.GetTasks() {
+			if task.Status == wiki.TASK_STATUS_CANCELLED {
+				continue
+			}
+			matches := task.IsInProgress() || task.HasCompletionForDate(now)
+			if task.Schedule != nil && task.Schedule.Repeat == "" && isToday(task.Schedule.Start) {
+				matches = true
+			}
+			for _, session := range task.Sessions {
+				if isToday(session.Start) {
+					matches = true
+					break
+				}
+			}
+			if matches {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"log"
+
+	"github.com/radovskyb/watcher"
+)
+
+// NewRootWatcher watches root recursively and calls onEvent for every change, start it with w.Start
+func NewRootWatcher(root string, onEvent func(event watcher.Event)) (*watcher.Watcher, error) {
+	w := watcher.New()
+	w.FilterOps(watcher.Create, watcher.Move, watcher.Remove, watcher.Write)
+
+	go func() {
+		for {
+			select {
+			case event := <-w.Event:
+				onEvent(event)
+			case err := <-w.Error:
+				log.Fatalln(err)
+			case <-w.Closed:
+				return
+			}
+		}
+	}()
+
+	if err := w.AddRecursive(root); err != nil {
+		w.Close()
+		return nil, err
+	}
+	return w, nil
+}
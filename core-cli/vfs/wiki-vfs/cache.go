@@ -0,0 +1,92 @@
+package wikivfs
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const DEFAULT_CACHE_SIZE = 256
+
+// markdownCacheKey ties a converted file to the source version it was generated from
+type markdownCacheKey struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+type markdownCacheEntry struct {
+	key      markdownCacheKey
+	markdown string
+}
+
+// markdownCache is an LRU of converted nodes, entries for outdated source versions never hit
+type markdownCache struct {
+	mutex      sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+func newMarkdownCache(maxEntries int) *markdownCache {
+	if maxEntries <= 0 {
+		maxEntries = DEFAULT_CACHE_SIZE
+	}
+	return &markdownCache{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+func (c *markdownCache) Get(key markdownCacheKey) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, exists := c.entries[key.path]
+	if !exists {
+		return "", false
+	}
+	entry := element.Value.(*markdownCacheEntry)
+	if !entry.key.modTime.Equal(key.modTime) || entry.key.size != key.size {
+		c.order.Remove(element)
+		delete(c.entries, key.path)
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return entry.markdown, true
+}
+
+func (c *markdownCache) Set(key markdownCacheKey, markdown string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, exists := c.entries[key.path]; exists {
+		element.Value = &markdownCacheEntry{key: key, markdown: markdown}
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key.path] = c.order.PushFront(&markdownCacheEntry{key: key, markdown: markdown})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*markdownCacheEntry).key.path)
+	}
+}
+
+func (c *markdownCache) Evict(path string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, exists := c.entries[path]; exists {
+		c.order.Remove(element)
+		delete(c.entries, path)
+	}
+}
+
+func (c *markdownCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,47 @@
+package wikivfs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownCache(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Hit only for the same source version", func(t *testing.T) {
+		cache := newMarkdownCache(2)
+		cache.Set(markdownCacheKey{path: "a", modTime: now, size: 1}, "a1")
+
+		value, ok := cache.Get(markdownCacheKey{path: "a", modTime: now, size: 1})
+		assert.True(t, ok)
+		assert.Equal(t, "a1", value)
+
+		_, ok = cache.Get(markdownCacheKey{path: "a", modTime: now.Add(time.Second), size: 1})
+		assert.False(t, ok)
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("Evict least recently used", func(t *testing.T) {
+		cache := newMarkdownCache(2)
+		cache.Set(markdownCacheKey{path: "a", modTime: now}, "a")
+		cache.Set(markdownCacheKey{path: "b", modTime: now}, "b")
+		cache.Get(markdownCacheKey{path: "a", modTime: now})
+		cache.Set(markdownCacheKey{path: "c", modTime: now}, "c")
+
+		_, ok := cache.Get(markdownCacheKey{path: "b", modTime: now})
+		assert.False(t, ok)
+		_, ok = cache.Get(markdownCacheKey{path: "a", modTime: now})
+		assert.True(t, ok)
+		assert.Equal(t, 2, cache.Len())
+	})
+
+	t.Run("Evict by path", func(t *testing.T) {
+		cache := newMarkdownCache(2)
+		cache.Set(markdownCacheKey{path: "a", modTime: now}, "a")
+		cache.Evict("a")
+		_, ok := cache.Get(markdownCacheKey{path: "a", modTime: now})
+		assert.False(t, ok)
+	})
+}
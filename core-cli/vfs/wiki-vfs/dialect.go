@@ -0,0 +1,54 @@
+package wikivfs
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/3rd/core/core-lib/wiki/markdown"
+)
+
+// ResolveLink returns the mount path of the node with the given name, without the .md suffix
+func (vfs *WikiVFS) ResolveLink(name string) (string, bool) {
+	nodes, err := vfs.wiki.GetNodes()
+	if err != nil {
+		return "", false
+	}
+	for _, node := range nodes {
+		if node.GetName() == name {
+			path, err := filepath.Rel(vfs.rootPath, node.GetPath())
+			if err != nil {
+				return "", false
+			}
+			return filepath.ToSlash(path), true
+		}
+	}
+	return "", false
+}
+
+// ResolveLinkName returns the name of the node at the given mount path
+func (vfs *WikiVFS) ResolveLinkName(path string) (string, bool) {
+	path = filepath.Join(vfs.rootPath, filepath.FromSlash(strings.TrimSuffix(path, ".md")))
+	nodes, err := vfs.wiki.GetNodes()
+	if err != nil {
+		return "", false
+	}
+	for _, node := range nodes {
+		if node.GetPath() == path {
+			return node.GetName(), true
+		}
+	}
+	return "", false
+}
+
+// dialect returns the markdown dialect selected by the mount options
+func (vfs *WikiVFS) dialect() markdown.Dialect {
+	dialect := markdown.Dialect{
+		// writable mounts already serve the meta block as frontmatter
+		Frontmatter:   vfs.options.Frontmatter && !vfs.options.Writable,
+		CheckboxStyle: vfs.options.CheckboxStyle,
+	}
+	if vfs.options.RewriteLinks {
+		dialect.Links = vfs
+	}
+	return dialect
+}
@@ -9,19 +9,25 @@ import (
 
 	"bazil.org/fuse"
 	"bazil.org/fuse/fs"
-	"github.com/3rd/core/core-lib/wiki/local"
 )
 
 type WikiVFSDir struct {
-	wiki *local.LocalWiki
+	vfs  *WikiVFS
 	path string
 }
 
-func (WikiVFSDir) Attr(ctx context.Context, a *fuse.Attr) error {
-	a.Inode = 1
+func (w WikiVFSDir) Attr(ctx context.Context, a *fuse.Attr) error {
+	a.Inode = w.vfs.inodes.Get(w.path)
 	a.Mode = os.ModeDir | 0o700
 	a.Uid = uint32(os.Getuid())
 	a.Gid = uint32(os.Getgid())
+
+	stat, err := os.Stat(w.path)
+	if err != nil {
+		return syscall.ENOENT
+	}
+	a.Mtime = stat.ModTime()
+	a.Atime, a.Ctime = getStatTimes(stat)
 	return nil
 }
 
@@ -33,53 +39,107 @@ func (w WikiVFSDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
 
 	var dirents []fuse.Dirent
 
-	wikiNodes, err := w.wiki.GetNodes()
+	wikiNodes, err := w.vfs.wiki.GetNodes()
 	if err != nil {
 		return nil, err
 	}
 
+	views := w.vfs.getDirViews(w.path)
+
 	for _, node := range files {
-		nodeType := fuse.DT_File
 		nodePath := filepath.Join(w.path, node.Name())
-		nodeName := node.Name()
 
 		if node.IsDir() {
-			nodeType = fuse.DT_Dir
-		} else {
-			// add '.md" if it's a wiki node
-			for _, wikiNode := range wikiNodes {
-				if nodePath == wikiNode.GetPath() {
-					nodeName = nodeName + ".md"
-					break
-				}
+			dirents = append(dirents, fuse.Dirent{
+				Inode: w.vfs.inodes.Get(nodePath),
+				Name:  node.Name(),
+				Type:  fuse.DT_Dir,
+			})
+			continue
+		}
+
+		// wiki nodes are listed once per view
+		isWikiNode := false
+		for _, wikiNode := range wikiNodes {
+			if nodePath == wikiNode.GetPath() {
+				isWikiNode = true
+				break
 			}
 		}
+		if !isWikiNode {
+			dirents = append(dirents, fuse.Dirent{
+				Inode: w.vfs.inodes.Get(nodePath),
+				Name:  node.Name(),
+				Type:  fuse.DT_File,
+			})
+			continue
+		}
+		for _, view := range views {
+			file := WikiVFSFile{vfs: w.vfs, path: nodePath, view: view}
+			dirents = append(dirents, fuse.Dirent{
+				Inode: file.inode(),
+				Name:  getViewName(node.Name(), view),
+				Type:  fuse.DT_File,
+			})
+		}
+	}
 
-		dirents = append(dirents, fuse.Dirent{
-			Name: nodeName,
-			Type: nodeType,
-		})
+	if w.isVirtualRoot() {
+		for name := range virtualRootDirs {
+			dirents = append(dirents, fuse.Dirent{Name: name, Type: fuse.DT_Dir})
+		}
 	}
 
 	return dirents, nil
 }
 
+func (w WikiVFSDir) isVirtualRoot() bool {
+	return w.vfs.options.VirtualDirs && w.path == w.vfs.rootPath
+}
+
 func (w WikiVFSDir) Lookup(ctx context.Context, name string) (fs.Node, error) {
-	// strip '.md' suffix
-	name = strings.TrimSuffix(name, ".md")
+	if w.isVirtualRoot() {
+		if kind, ok := virtualRootDirs[name]; ok {
+			return WikiVFSVirtualDir{vfs: w.vfs, kind: kind}, nil
+		}
+	}
+
+	views := w.vfs.getDirViews(w.path)
 
-	stat, err := os.Stat(filepath.Join(w.path, name))
+	// the name of a wiki node in one of the enabled views
+	for _, view := range views {
+		nodePath := filepath.Join(w.path, name)
+		switch view {
+		case FILE_VIEW_MARKDOWN:
+			nodePath = strings.TrimSuffix(nodePath, ".md")
+		case FILE_VIEW_HTML:
+			nodePath = strings.TrimSuffix(nodePath, ".html")
+		}
+		if getViewName(filepath.Base(nodePath), view) != name {
+			continue
+		}
+		file := WikiVFSFile{vfs: w.vfs, path: nodePath, view: view}
+		if wikiNode, err := file.getWikiNode(); err == nil && wikiNode != nil {
+			return file, nil
+		}
+	}
+
+	path := filepath.Join(w.path, name)
+	stat, err := os.Stat(path)
 	if err != nil {
 		return nil, syscall.ENOENT
 	}
 	if stat.IsDir() {
 		return WikiVFSDir{
-			wiki: w.wiki,
-			path: filepath.Join(w.path, name),
+			vfs:  w.vfs,
+			path: path,
 		}, nil
 	}
-	return WikiVFSFile{
-		wiki: w.wiki,
-		path: filepath.Join(w.path, name),
-	}, nil
+
+	// wiki nodes are only reachable through their views
+	file := WikiVFSFile{vfs: w.vfs, path: path, view: FILE_VIEW_RAW}
+	if wikiNode, err := file.getWikiNode(); err != nil || wikiNode != nil {
+		return nil, syscall.ENOENT
+	}
+	return file, nil
 }
@@ -4,104 +4,200 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strings"
-	"sync"
+	"path/filepath"
 
 	"bazil.org/fuse"
 	"bazil.org/fuse/fuseutil"
 	"github.com/3rd/core/core-lib/wiki/local"
+	"github.com/3rd/core/core-lib/wiki/markdown"
 )
 
 type WikiVFSFile struct {
-	wiki     *local.LocalWiki
-	path     string
-	markdown *string
+	vfs  *WikiVFS
+	path string
+	view string
 }
 
-var (
-	markdownCache = make(map[string]*string)
-	cacheMutex    sync.RWMutex
-)
+// inode keeps the historical inode for the markdown view, other views get their own
+func (w WikiVFSFile) inode() uint64 {
+	if w.view == FILE_VIEW_MARKDOWN {
+		return w.vfs.inodes.Get(w.path)
+	}
+	return w.vfs.inodes.Get(w.path + "#" + w.view)
+}
+
+func (w WikiVFSFile) getWikiNode() (*local.LocalNode, error) {
+	path := w.path
+	wikiNodes, err := w.vfs.wiki.GetLocalNodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wiki nodes: %w", err)
+	}
+	for _, wikiNode := range wikiNodes {
+		if path == wikiNode.GetPath() {
+			return wikiNode, nil
+		}
+	}
+	return nil, nil
+}
 
-func (w WikiVFSFile) Convert() error {
-	cacheMutex.RLock()
-	cachedMarkdown, exists := markdownCache[w.path]
-	cacheMutex.RUnlock()
+// getMarkdown converts a wiki node to markdown, before any dialect is applied
+func (w WikiVFSFile) getMarkdown(wikiNode *local.LocalNode) (string, error) {
+	if w.vfs.options.Writable {
+		text, err := wikiNode.Text()
+		if err != nil {
+			return "", fmt.Errorf("failed to read node: %w", err)
+		}
+		return markdown.FromSyslang(text), nil
+	}
+	return wikiNode.ToMarkdown(), nil
+}
 
-	if exists {
-		w.markdown = cachedMarkdown
-		return nil
+// Convert returns the markdown for wiki nodes, or nil for other files which are served raw
+func (w WikiVFSFile) Convert() (*string, error) {
+	stat, err := os.Stat(w.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	key := markdownCacheKey{path: w.path, modTime: stat.ModTime(), size: stat.Size()}
+	if cached, ok := w.vfs.cache.Get(key); ok {
+		return &cached, nil
 	}
 
-	cacheMutex.Lock()
-	defer cacheMutex.Unlock()
+	wikiNode, err := w.getWikiNode()
+	if err != nil {
+		return nil, err
+	}
+	if wikiNode == nil {
+		return nil, nil
+	}
 
-	path := strings.TrimSuffix(w.path, ".md")
-	wikiNodes, err := w.wiki.GetNodes()
+	result, err := w.getMarkdown(wikiNode)
 	if err != nil {
-		return fmt.Errorf("failed to get wiki nodes: %w", err)
+		return nil, err
+	}
+	if dialect := w.vfs.dialect(); !dialect.IsZero() {
+		result = dialect.Apply(result, wikiNode.GetMeta())
 	}
+	w.vfs.cache.Set(key, result)
+	return &result, nil
+}
 
-	for _, wikiNode := range wikiNodes {
-		if path == wikiNode.GetPath() {
-			wikiNode.Parse("full")
-			markdown := wikiNode.ToMarkdown()
-			markdownCache[w.path] = &markdown
-			w.markdown = &markdown
-			return nil
+// toHTML renders the node markdown, linking other nodes to their html view,
+// links are resolved by node name so the markdown must not have the dialect applied
+func (w WikiVFSFile) toHTML(wikiNode *local.LocalNode) ([]byte, error) {
+	text, err := w.getMarkdown(wikiNode)
+	if err != nil {
+		return nil, err
+	}
+	linkHref := func(name string) (string, bool) {
+		path, ok := w.vfs.ResolveLink(name)
+		if !ok {
+			return "", false
+		}
+		href, err := filepath.Rel(filepath.Dir(w.path), filepath.Join(w.vfs.rootPath, path))
+		if err != nil {
+			return "", false
+		}
+		return filepath.ToSlash(href) + ".html", true
+	}
+	body := markdown.ToHTML(text, markdown.HTMLOptions{LinkHref: linkHref})
+	return []byte(markdown.HTMLPage(wikiNode.GetName(), body)), nil
+}
+
+// content returns the bytes served for this file
+func (w WikiVFSFile) content() ([]byte, error) {
+	if w.view == FILE_VIEW_RAW {
+		content, err := os.ReadFile(w.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		return content, nil
+	}
+
+	if w.view == FILE_VIEW_HTML {
+		wikiNode, err := w.getWikiNode()
+		if err != nil {
+			return nil, err
+		}
+		if wikiNode != nil {
+			return w.toHTML(wikiNode)
+		}
+	} else {
+		converted, err := w.Convert()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert: %w", err)
+		}
+		if converted != nil {
+			return []byte(*converted), nil
 		}
 	}
 
-	return fmt.Errorf("no matching wiki node found for path: %s", path)
+	// fallback to real content
+	content, err := os.ReadFile(w.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return content, nil
 }
 
 func (w WikiVFSFile) Attr(ctx context.Context, a *fuse.Attr) error {
-	a.Inode = 2
+	a.Inode = w.inode()
 	a.Mode = 0o700
+	if w.vfs.options.Writable {
+		a.Mode = 0o600
+	}
+	if w.view == FILE_VIEW_HTML {
+		a.Mode = 0o400
+	}
 	a.Uid = uint32(os.Getuid())
 	a.Gid = uint32(os.Getgid())
 
-	if err := w.Convert(); err != nil {
-		return fmt.Errorf("failed to convert: %w", err)
+	stat, err := os.Stat(w.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
 	}
+	a.Mtime = stat.ModTime()
+	a.Atime, a.Ctime = getStatTimes(stat)
 
-	cacheMutex.RLock()
-	markdown := markdownCache[w.path]
-	cacheMutex.RUnlock()
+	if pending := w.vfs.getPendingWrite(w.writeKey()); pending != nil {
+		a.Size = uint64(pending.size())
+		return nil
+	}
 
-	if markdown != nil {
-		a.Size = uint64(len(*markdown))
-	} else {
-		stat, err := os.Stat(w.path)
+	switch w.view {
+	case FILE_VIEW_RAW:
+		a.Size = uint64(stat.Size())
+	case FILE_VIEW_HTML:
+		content, err := w.content()
 		if err != nil {
-			return fmt.Errorf("failed to stat file: %w", err)
+			return err
+		}
+		a.Size = uint64(len(content))
+	default:
+		converted, err := w.Convert()
+		if err != nil {
+			return fmt.Errorf("failed to convert: %w", err)
+		}
+		if converted != nil {
+			a.Size = uint64(len(*converted))
+		} else {
+			a.Size = uint64(stat.Size())
 		}
-		a.Size = uint64(stat.Size())
 	}
 
 	return nil
 }
 
 func (w WikiVFSFile) Read(ctx context.Context, req *fuse.ReadRequest, resp *fuse.ReadResponse) error {
-	if err := w.Convert(); err != nil {
-		return fmt.Errorf("failed to convert: %w", err)
-	}
-
-	cacheMutex.RLock()
-	markdown := markdownCache[w.path]
-	cacheMutex.RUnlock()
-
-	if markdown != nil {
-		fuseutil.HandleRead(req, resp, []byte(*markdown))
+	if pending := w.vfs.getPendingWrite(w.writeKey()); pending != nil {
+		fuseutil.HandleRead(req, resp, pending.bytes())
 		return nil
 	}
 
-	// fallback to real content
-	content, err := os.ReadFile(w.path)
+	content, err := w.content()
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return err
 	}
-
 	fuseutil.HandleRead(req, resp, content)
 	return nil
 }
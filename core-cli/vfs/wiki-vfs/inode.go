@@ -0,0 +1,50 @@
+package wikivfs
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+const ROOT_INODE = 1
+
+// inodeAllocator hands out inodes derived from the path hash so they stay stable across remounts
+type inodeAllocator struct {
+	mutex    sync.Mutex
+	rootPath string
+	inodes   map[string]uint64
+	used     map[uint64]string
+}
+
+func newInodeAllocator(rootPath string) *inodeAllocator {
+	return &inodeAllocator{
+		rootPath: rootPath,
+		inodes:   map[string]uint64{rootPath: ROOT_INODE},
+		used:     map[uint64]string{ROOT_INODE: rootPath},
+	}
+}
+
+func (a *inodeAllocator) Get(path string) uint64 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if inode, exists := a.inodes[path]; exists {
+		return inode
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(path))
+	inode := hash.Sum64()
+	// probe on collision, 0 and the root inode are reserved
+	for {
+		if inode > ROOT_INODE {
+			if _, taken := a.used[inode]; !taken {
+				break
+			}
+		}
+		inode++
+	}
+
+	a.inodes[path] = inode
+	a.used[inode] = path
+	return inode
+}
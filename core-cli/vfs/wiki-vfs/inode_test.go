@@ -0,0 +1,31 @@
+package wikivfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInodeAllocator(t *testing.T) {
+	t.Run("Root is inode 1", func(t *testing.T) {
+		allocator := newInodeAllocator("/wiki")
+		assert.Equal(t, uint64(ROOT_INODE), allocator.Get("/wiki"))
+	})
+
+	t.Run("Stable and unique per path", func(t *testing.T) {
+		allocator := newInodeAllocator("/wiki")
+		a := allocator.Get("/wiki/a")
+		b := allocator.Get("/wiki/b")
+		assert.NotEqual(t, a, b)
+		assert.Equal(t, a, allocator.Get("/wiki/a"))
+		assert.Equal(t, a, newInodeAllocator("/wiki").Get("/wiki/a"))
+	})
+
+	t.Run("Probe on collision", func(t *testing.T) {
+		allocator := newInodeAllocator("/wiki")
+		a := allocator.Get("/wiki/a")
+		// simulate another path already owning the hash
+		delete(allocator.inodes, "/wiki/a")
+		assert.Equal(t, a+1, allocator.Get("/wiki/a"))
+	})
+}
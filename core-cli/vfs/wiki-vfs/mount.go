@@ -0,0 +1,32 @@
+package wikivfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+
+	"bazil.org/fuse"
+)
+
+// IsStaleMount reports whether mountPoint is a fuse mount whose server is gone
+func IsStaleMount(mountPoint string) bool {
+	_, err := os.Stat(mountPoint)
+	return errors.Is(err, syscall.ENOTCONN)
+}
+
+// CleanupStaleMount unmounts mountPoint if a previous server died without unmounting it
+func CleanupStaleMount(mountPoint string) (bool, error) {
+	if !IsStaleMount(mountPoint) {
+		return false, nil
+	}
+	if err := fuse.Unmount(mountPoint); err != nil {
+		return false, fmt.Errorf("failed to unmount stale mount %s: %w", mountPoint, err)
+	}
+	return true, nil
+}
+
+// Unmount detaches the mount point, which makes Mount return
+func (vfs *WikiVFS) Unmount() error {
+	return fuse.Unmount(vfs.mountPoint)
+}
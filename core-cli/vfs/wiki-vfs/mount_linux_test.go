@@ -0,0 +1,130 @@
+package wikivfs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bazil.org/fuse"
+	"github.com/3rd/core/core-lib/wiki/local"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requireFuse skips integration tests on hosts that cannot mount
+func requireFuse(t *testing.T) {
+	if _, err := os.Stat("/dev/fuse"); err != nil {
+		t.Skip("/dev/fuse not available")
+	}
+	if _, err := exec.LookPath("fusermount"); err != nil {
+		t.Skip("fusermount not available")
+	}
+}
+
+func mountTestWiki(t *testing.T) (*WikiVFS, string, chan error) {
+	mountPoint := t.TempDir()
+	vfs, served := mountTestWikiAt(t, mountPoint)
+	return vfs, mountPoint, served
+}
+
+func mountTestWikiAt(t *testing.T, mountPoint string) (*WikiVFS, chan error) {
+	root, err := filepath.Abs("../../../core-lib/test-data/wiki/default")
+	require.NoError(t, err)
+	wikiInstance, err := local.NewLocalWiki(local.LocalWikiConfig{Root: root, Parse: local.PARSE_MODE_META})
+	require.NoError(t, err)
+
+	vfs, err := NewWikiVFS(wikiInstance, root, mountPoint, WikiVFSOptions{CacheSize: DEFAULT_CACHE_SIZE, VirtualDirs: true})
+	require.NoError(t, err)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- vfs.Mount()
+	}()
+	return vfs, served
+}
+
+func waitForMount(t *testing.T, mountPoint string) {
+	require.Eventually(t, func() bool {
+		entries, err := os.ReadDir(mountPoint)
+		return err == nil && len(entries) > 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+// TestMountProcess serves the test wiki on WIKIVFS_TEST_MOUNT until the process is killed,
+// it's started by the stale mount test and does nothing in a regular run
+func TestMountProcess(t *testing.T) {
+	mountPoint := os.Getenv("WIKIVFS_TEST_MOUNT")
+	if mountPoint == "" {
+		return
+	}
+	_, served := mountTestWikiAt(t, mountPoint)
+	<-served
+}
+
+func TestMount(t *testing.T) {
+	requireFuse(t)
+
+	t.Run("Serve and unmount", func(t *testing.T) {
+		vfs, mountPoint, served := mountTestWiki(t)
+		defer vfs.Close()
+
+		waitForMount(t, mountPoint)
+		entries, err := os.ReadDir(mountPoint)
+		require.NoError(t, err)
+
+		names := []string{}
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.Contains(t, names, "nested")
+		assert.Contains(t, names, VIRTUAL_DIR_TASKS)
+
+		require.NoError(t, vfs.Unmount())
+		select {
+		case err := <-served:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Mount did not return after Unmount")
+		}
+		assert.False(t, IsStaleMount(mountPoint))
+	})
+
+	t.Run("Cleanup recovers a stale mount", func(t *testing.T) {
+		mountPoint := t.TempDir()
+		t.Cleanup(func() { fuse.Unmount(mountPoint) })
+
+		server := exec.Command(os.Args[0], "-test.run=^TestMountProcess$")
+		server.Env = append(os.Environ(), "WIKIVFS_TEST_MOUNT="+mountPoint)
+		require.NoError(t, server.Start())
+		t.Cleanup(func() { server.Process.Kill() })
+		waitForMount(t, mountPoint)
+
+		// a killed server can't unmount, the kernel keeps the mount without a connection
+		require.NoError(t, server.Process.Kill())
+		server.Wait()
+		require.Eventually(t, func() bool { return IsStaleMount(mountPoint) }, 5*time.Second, 50*time.Millisecond)
+
+		cleaned, err := CleanupStaleMount(mountPoint)
+		require.NoError(t, err)
+		assert.True(t, cleaned)
+		assert.False(t, IsStaleMount(mountPoint))
+		entries, err := os.ReadDir(mountPoint)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+
+		// the mount point can be served again
+		vfs, served := mountTestWikiAt(t, mountPoint)
+		defer vfs.Close()
+		waitForMount(t, mountPoint)
+		require.NoError(t, vfs.Unmount())
+		assert.NoError(t, <-served)
+	})
+
+	t.Run("Cleanup ignores healthy directories", func(t *testing.T) {
+		cleaned, err := CleanupStaleMount(t.TempDir())
+		assert.NoError(t, err)
+		assert.False(t, cleaned)
+	})
+}
@@ -0,0 +1,15 @@
+package wikivfs
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func getStatTimes(info os.FileInfo) (atime time.Time, ctime time.Time) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.ModTime(), info.ModTime()
+	}
+	return time.Unix(stat.Atim.Unix()), time.Unix(stat.Ctim.Unix())
+}
@@ -0,0 +1,72 @@
+package wikivfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// file views, each enabled view exposes wiki nodes under its own name
+const (
+	FILE_VIEW_RAW      = "raw"
+	FILE_VIEW_MARKDOWN = "md"
+	FILE_VIEW_HTML     = "html"
+)
+
+// VIEWS_CONFIG_FILE selects the views of a directory and its subdirectories, e.g. "raw,md"
+const VIEWS_CONFIG_FILE = ".views"
+
+var fileViews = []string{FILE_VIEW_RAW, FILE_VIEW_MARKDOWN, FILE_VIEW_HTML}
+
+var defaultViews = []string{FILE_VIEW_MARKDOWN}
+
+// ParseViews parses a comma or whitespace separated list of views
+func ParseViews(text string) ([]string, error) {
+	views := []string{}
+	for _, view := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\t'
+	}) {
+		if !slices.Contains(fileViews, view) {
+			return nil, fmt.Errorf("invalid view: %s", view)
+		}
+		if !slices.Contains(views, view) {
+			views = append(views, view)
+		}
+	}
+	if len(views) == 0 {
+		return nil, fmt.Errorf("no views selected")
+	}
+	return views, nil
+}
+
+// getViewName returns the name a node file is exposed under for a view
+func getViewName(name string, view string) string {
+	switch view {
+	case FILE_VIEW_MARKDOWN:
+		return name + ".md"
+	case FILE_VIEW_HTML:
+		return name + ".html"
+	}
+	return name
+}
+
+// getDirViews returns the views of the closest config file between dir and the root, or the mount defaults
+func (vfs *WikiVFS) getDirViews(dir string) []string {
+	for {
+		if content, err := os.ReadFile(filepath.Join(dir, VIEWS_CONFIG_FILE)); err == nil {
+			if views, err := ParseViews(string(content)); err == nil {
+				return views
+			}
+		}
+		if dir == vfs.rootPath || !strings.HasPrefix(dir, vfs.rootPath) {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	if len(vfs.options.Views) > 0 {
+		return vfs.options.Views
+	}
+	return defaultViews
+}
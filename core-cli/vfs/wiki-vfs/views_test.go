@@ -0,0 +1,67 @@
+package wikivfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/3rd/core/core-lib/wiki/local"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViews(t *testing.T) {
+	t.Run("Parse views", func(t *testing.T) {
+		views, err := ParseViews("raw, md\nhtml md")
+		require.NoError(t, err)
+		assert.Equal(t, []string{FILE_VIEW_RAW, FILE_VIEW_MARKDOWN, FILE_VIEW_HTML}, views)
+
+		_, err = ParseViews("pdf")
+		assert.Error(t, err)
+		_, err = ParseViews(" ")
+		assert.Error(t, err)
+	})
+
+	t.Run("View names", func(t *testing.T) {
+		assert.Equal(t, "node", getViewName("node", FILE_VIEW_RAW))
+		assert.Equal(t, "node.md", getViewName("node", FILE_VIEW_MARKDOWN))
+		assert.Equal(t, "node.html", getViewName("node", FILE_VIEW_HTML))
+	})
+
+	t.Run("Closest config wins", func(t *testing.T) {
+		root := t.TempDir()
+		nested := filepath.Join(root, "a", "b")
+		require.NoError(t, os.MkdirAll(nested, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "a", VIEWS_CONFIG_FILE), []byte("raw,html\n"), 0o644))
+
+		vfs := &WikiVFS{rootPath: root, options: WikiVFSOptions{Views: []string{FILE_VIEW_MARKDOWN}}}
+		assert.Equal(t, []string{FILE_VIEW_MARKDOWN}, vfs.getDirViews(root))
+		assert.Equal(t, []string{FILE_VIEW_RAW, FILE_VIEW_HTML}, vfs.getDirViews(nested))
+	})
+
+	t.Run("HTML links resolve with rewritten markdown links", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "alpha"), []byte("@meta\ntitle: Alpha\n@end\nSee [[Beta]].\n"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "beta"), []byte("@meta\ntitle: Beta\n@end\n"), 0o644))
+		wikiInstance, err := local.NewLocalWiki(local.LocalWikiConfig{Root: root, Parse: local.PARSE_MODE_META})
+		require.NoError(t, err)
+
+		vfs := &WikiVFS{
+			rootPath: root,
+			wiki:     wikiInstance,
+			options:  WikiVFSOptions{RewriteLinks: true},
+			cache:    newMarkdownCache(DEFAULT_CACHE_SIZE),
+			inodes:   newInodeAllocator(root),
+		}
+		path := filepath.Join(root, "alpha")
+
+		markdownContent, err := WikiVFSFile{vfs: vfs, path: path, view: FILE_VIEW_MARKDOWN}.content()
+		require.NoError(t, err)
+		assert.Contains(t, string(markdownContent), "[[beta|Beta]]")
+
+		htmlContent, err := WikiVFSFile{vfs: vfs, path: path, view: FILE_VIEW_HTML}.content()
+		require.NoError(t, err)
+		assert.Contains(t, string(htmlContent), `href="beta.html"`)
+		assert.NotContains(t, string(htmlContent), "link-missing")
+	})
+}
@@ -0,0 +1,292 @@
+package wikivfs
+
+import (
+	"context"
+	"core/utils"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"bazil.org/fuse"
+	"bazil.org/fuse/fs"
+	"bazil.org/fuse/fuseutil"
+	"github.com/3rd/core/core-lib/wiki"
+	"github.com/3rd/core/core-lib/wiki/local"
+)
+
+// synthetic directories exposed at the mount root
+const (
+	VIRTUAL_DIR_BY_TYPE = "_by-type"
+	VIRTUAL_DIR_TASKS   = "_tasks"
+	VIRTUAL_DIR_META    = "_meta"
+)
+
+type VIRTUAL_KIND string
+
+const (
+	VIRTUAL_KIND_BY_TYPE_ROOT  VIRTUAL_KIND = "by-type-root"
+	VIRTUAL_KIND_BY_TYPE       VIRTUAL_KIND = "by-type"
+	VIRTUAL_KIND_TASKS_ROOT    VIRTUAL_KIND = "tasks-root"
+	VIRTUAL_KIND_TASKS_BY_NODE VIRTUAL_KIND = "tasks-by-node"
+	VIRTUAL_KIND_META_ROOT     VIRTUAL_KIND = "meta-root"
+	VIRTUAL_KIND_TASKS_ACTIVE  VIRTUAL_KIND = "tasks-active"
+	VIRTUAL_KIND_TASKS_TODAY   VIRTUAL_KIND = "tasks-today"
+	VIRTUAL_KIND_TASKS_NODE    VIRTUAL_KIND = "tasks-node"
+	VIRTUAL_KIND_META_NODE     VIRTUAL_KIND = "meta-node"
+)
+
+var virtualRootDirs = map[string]VIRTUAL_KIND{
+	VIRTUAL_DIR_BY_TYPE: VIRTUAL_KIND_BY_TYPE_ROOT,
+	VIRTUAL_DIR_TASKS:   VIRTUAL_KIND_TASKS_ROOT,
+	VIRTUAL_DIR_META:    VIRTUAL_KIND_META_ROOT,
+}
+
+// nodes are fuse map keys, so virtual nodes are plain comparable values
+type WikiVFSVirtualDir struct {
+	vfs  *WikiVFS
+	kind VIRTUAL_KIND
+	arg  string
+}
+
+type WikiVFSVirtualFile struct {
+	vfs  *WikiVFS
+	kind VIRTUAL_KIND
+	arg  string
+}
+
+type virtualEntry struct {
+	name string
+	node fs.Node
+	kind fuse.DirentType
+}
+
+// getVirtualName maps a node ID to a single path segment
+func getVirtualName(node *local.LocalNode) string {
+	return strings.ReplaceAll(node.GetID(), "/", "-")
+}
+
+func (vfs *WikiVFS) getParsedNodes(mode local.PARSE_MODE) ([]*local.LocalNode, error) {
+	nodes, err := vfs.wiki.GetLocalNodes()
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if err := node.EnsureParsed(mode); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+func (d WikiVFSVirtualDir) Attr(ctx context.Context, a *fuse.Attr) error {
+	a.Inode = d.vfs.inodes.Get(fmt.Sprintf("virtual:%s:%s", d.kind, d.arg))
+	a.Mode = os.ModeDir | 0o500
+	a.Uid = uint32(os.Getuid())
+	a.Gid = uint32(os.Getgid())
+	a.Mtime = time.Now()
+	return nil
+}
+
+func (d WikiVFSVirtualDir) entries() ([]virtualEntry, error) {
+	entries := []virtualEntry{}
+
+	switch d.kind {
+	case VIRTUAL_KIND_BY_TYPE_ROOT:
+		nodes, err := d.vfs.getParsedNodes(local.PARSE_MODE_META)
+		if err != nil {
+			return nil, err
+		}
+		types := map[string]bool{}
+		for _, node := range nodes {
+			if nodeType := node.GetMeta()["type"]; nodeType != "" {
+				types[nodeType] = true
+			}
+		}
+		for nodeType := range types {
+			entries = append(entries, virtualEntry{
+				name: nodeType,
+				node: WikiVFSVirtualDir{vfs: d.vfs, kind: VIRTUAL_KIND_BY_TYPE, arg: nodeType},
+				kind: fuse.DT_Dir,
+			})
+		}
+
+	case VIRTUAL_KIND_BY_TYPE:
+		nodes, err := d.vfs.getParsedNodes(local.PARSE_MODE_META)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			if node.GetMeta()["type"] == d.arg {
+				entries = append(entries, virtualEntry{
+					name: getVirtualName(node) + ".md",
+					node: WikiVFSFile{vfs: d.vfs, path: node.GetPath(), view: FILE_VIEW_MARKDOWN},
+					kind: fuse.DT_File,
+				})
+			}
+		}
+
+	case VIRTUAL_KIND_TASKS_ROOT:
+		entries = append(entries,
+			virtualEntry{name: "active", node: WikiVFSVirtualFile{vfs: d.vfs, kind: VIRTUAL_KIND_TASKS_ACTIVE}, kind: fuse.DT_File},
+			virtualEntry{name: "today", node: WikiVFSVirtualFile{vfs: d.vfs, kind: VIRTUAL_KIND_TASKS_TODAY}, kind: fuse.DT_File},
+			virtualEntry{name: "by-node", node: WikiVFSVirtualDir{vfs: d.vfs, kind: VIRTUAL_KIND_TASKS_BY_NODE}, kind: fuse.DT_Dir},
+		)
+
+	case VIRTUAL_KIND_TASKS_BY_NODE:
+		nodes, err := d.vfs.getParsedNodes(local.PARSE_MODE_META)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			if len(node.GetTasks()) == 0 {
+				continue
+			}
+			entries = append(entries, virtualEntry{
+				name: getVirtualName(node),
+				node: WikiVFSVirtualFile{vfs: d.vfs, kind: VIRTUAL_KIND_TASKS_NODE, arg: node.GetID()},
+				kind: fuse.DT_File,
+			})
+		}
+
+	case VIRTUAL_KIND_META_ROOT:
+		nodes, err := d.vfs.getParsedNodes(local.PARSE_MODE_META)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			entries = append(entries, virtualEntry{
+				name: getVirtualName(node) + ".json",
+				node: WikiVFSVirtualFile{vfs: d.vfs, kind: VIRTUAL_KIND_META_NODE, arg: node.GetID()},
+				kind: fuse.DT_File,
+			})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	return entries, nil
+}
+
+func (d WikiVFSVirtualDir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	entries, err := d.entries()
+	if err != nil {
+		return nil, err
+	}
+	dirents := []fuse.Dirent{}
+	for _, entry := range entries {
+		dirents = append(dirents, fuse.Dirent{Name: entry.name, Type: entry.kind})
+	}
+	return dirents, nil
+}
+
+func (d WikiVFSVirtualDir) Lookup(ctx context.Context, name string) (fs.Node, error) {
+	entries, err := d.entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.name == name {
+			return entry.node, nil
+		}
+	}
+	return nil, syscall.ENOENT
+}
+
+func formatTaskLines(tasks []*wiki.Task) []byte {
+	builder := strings.Builder{}
+	for _, task := range tasks {
+		fmt.Fprintf(&builder, "%s - %s\n", task.Node.GetName(), task.Text)
+	}
+	return []byte(builder.String())
+}
+
+func (f WikiVFSVirtualFile) content() ([]byte, error) {
+	now := time.Now()
+
+	switch f.kind {
+	case VIRTUAL_KIND_TASKS_ACTIVE:
+		nodes, err := f.vfs.getParsedNodes(local.PARSE_MODE_META)
+		if err != nil {
+			return nil, err
+		}
+		active, _ := utils.FindActiveTasks(nodes, false, now)
+		return formatTaskLines(active), nil
+
+	case VIRTUAL_KIND_TASKS_TODAY:
+		nodes, err := f.vfs.getParsedNodes(local.PARSE_MODE_META)
+		if err != nil {
+			return nil, err
+		}
+		return formatTaskLines(utils.FindTodayTasks(nodes, now)), nil
+
+	case VIRTUAL_KIND_TASKS_NODE:
+		node, err := f.vfs.wiki.GetLocalNode(f.arg)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			return nil, syscall.ENOENT
+		}
+		if err := node.EnsureParsed(local.PARSE_MODE_FULL); err != nil {
+			return nil, err
+		}
+		builder := strings.Builder{}
+		for _, task := range node.GetTasks() {
+			fmt.Fprintf(&builder, "%d: %s\n", task.LineNumber+1, strings.TrimSpace(task.LineText))
+		}
+		return []byte(builder.String()), nil
+
+	case VIRTUAL_KIND_META_NODE:
+		node, err := f.vfs.wiki.GetNode(f.arg)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			return nil, syscall.ENOENT
+		}
+		data, err := json.MarshalIndent(wiki.NewJSONNode(node), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+
+	return nil, syscall.ENOENT
+}
+
+func (f WikiVFSVirtualFile) Attr(ctx context.Context, a *fuse.Attr) error {
+	content, err := f.content()
+	if err != nil {
+		return err
+	}
+	a.Inode = f.vfs.inodes.Get(fmt.Sprintf("virtual:%s:%s", f.kind, f.arg))
+	a.Mode = 0o400
+	a.Uid = uint32(os.Getuid())
+	a.Gid = uint32(os.Getgid())
+	a.Size = uint64(len(content))
+	a.Mtime = time.Now()
+	return nil
+}
+
+func (f WikiVFSVirtualFile) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenResponse) (fs.Handle, error) {
+	if !req.Flags.IsReadOnly() {
+		return nil, syscall.EROFS
+	}
+	// generated on every read, never let the kernel cache it
+	resp.Flags |= fuse.OpenDirectIO
+	return f, nil
+}
+
+func (f WikiVFSVirtualFile) Read(ctx context.Context, req *fuse.ReadRequest, resp *fuse.ReadResponse) error {
+	content, err := f.content()
+	if err != nil {
+		return err
+	}
+	fuseutil.HandleRead(req, resp, content)
+	return nil
+}
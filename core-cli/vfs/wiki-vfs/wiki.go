@@ -1,38 +1,75 @@
 package wikivfs
 
 import (
+	"log"
+	"sync"
+
 	"bazil.org/fuse"
 	"bazil.org/fuse/fs"
 	_ "bazil.org/fuse/fs/fstestutil"
 	"github.com/3rd/core/core-lib/wiki/local"
 )
 
+type WikiVFSOptions struct {
+	// Writable serves reversible Markdown and converts saved files back to Syslang
+	Writable bool
+	// CacheSize bounds the number of converted nodes kept in memory
+	CacheSize int
+	// VirtualDirs exposes the _by-type, _tasks and _meta query directories at the root
+	VirtualDirs bool
+	// Frontmatter prepends node meta as YAML frontmatter to read-only nodes
+	Frontmatter bool
+	// RewriteLinks turns [[name]] links into paths that resolve inside the mount
+	RewriteLinks bool
+	// Views lists the default node views, directories can override them with a VIEWS_CONFIG_FILE
+	Views []string
+	// CheckboxStyle selects how task markers are written, see markdown.CHECKBOX_STYLE_*
+	CheckboxStyle string
+}
+
 type WikiFS struct {
-	rootPath string
-	wiki     *local.LocalWiki
+	vfs *WikiVFS
 }
 
 func (w WikiFS) Root() (fs.Node, error) {
 	return WikiVFSDir{
-		path: w.rootPath,
-		wiki: w.wiki,
+		vfs:  w.vfs,
+		path: w.vfs.rootPath,
 	}, nil
 }
 
 type WikiVFS struct {
-	rootPath   string
-	wiki       *local.LocalWiki
-	mountPoint string
-	conn       *fuse.Conn
+	rootPath    string
+	wiki        *local.LocalWiki
+	mountPoint  string
+	options     WikiVFSOptions
+	conn        *fuse.Conn
+	server      *fs.Server
+	cache       *markdownCache
+	inodes      *inodeAllocator
+	writes      map[string]*pendingWrite
+	writesMutex sync.Mutex
 }
 
-func NewWikiVFS(wiki *local.LocalWiki, rootPath string, mountPoint string) (*WikiVFS, error) {
+func NewWikiVFS(wiki *local.LocalWiki, rootPath string, mountPoint string, options WikiVFSOptions) (*WikiVFS, error) {
 	vfs := WikiVFS{
 		rootPath:   rootPath,
 		wiki:       wiki,
 		mountPoint: mountPoint,
+		options:    options,
+		cache:      newMarkdownCache(options.CacheSize),
+		inodes:     newInodeAllocator(rootPath),
+		writes:     map[string]*pendingWrite{},
+	}
+	if _, err := CleanupStaleMount(mountPoint); err != nil {
+		return nil, err
+	}
+
+	mountOptions := []fuse.MountOption{}
+	if !options.Writable {
+		mountOptions = append(mountOptions, fuse.ReadOnly())
 	}
-	conn, err := fuse.Mount(mountPoint)
+	conn, err := fuse.Mount(mountPoint, mountOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -40,12 +77,29 @@ func NewWikiVFS(wiki *local.LocalWiki, rootPath string, mountPoint string) (*Wik
 	return &vfs, nil
 }
 
+// Mount serves the file system until it is unmounted
 func (vfs *WikiVFS) Mount() error {
-	err := fs.Serve(vfs.conn, WikiFS{
-		rootPath: vfs.rootPath,
-		wiki:     vfs.wiki,
-	})
-	return err
+	vfs.server = fs.New(vfs.conn, nil)
+	return vfs.server.Serve(WikiFS{vfs: vfs})
+}
+
+// Invalidate drops the converted content of a changed source file, both locally and in the kernel page cache
+func (vfs *WikiVFS) Invalidate(path string) {
+	vfs.cache.Evict(path)
+	if vfs.server == nil {
+		return
+	}
+
+	for _, view := range fileViews {
+		node := WikiVFSFile{vfs: vfs, path: path, view: view}
+		// ErrNotCached means the kernel never looked the file up, nothing to do
+		if err := vfs.server.InvalidateNodeData(node); err != nil && err != fuse.ErrNotCached {
+			log.Printf("failed to invalidate %s: %v", path, err)
+		}
+		if err := vfs.server.InvalidateNodeAttr(node); err != nil && err != fuse.ErrNotCached {
+			log.Printf("failed to invalidate %s: %v", path, err)
+		}
+	}
 }
 
 func (vfs *WikiVFS) Close() {
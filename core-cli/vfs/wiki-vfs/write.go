@@ -0,0 +1,211 @@
+package wikivfs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"syscall"
+
+	"bazil.org/fuse"
+	"bazil.org/fuse/fs"
+	corefs "github.com/3rd/core/core-lib/fs"
+	"github.com/3rd/core/core-lib/wiki/markdown"
+)
+
+// pendingWrite buffers the content of a file opened for writing until it's flushed
+type pendingWrite struct {
+	mutex   sync.Mutex
+	data    []byte
+	dirty   bool
+	handles int
+}
+
+func (p *pendingWrite) size() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.data)
+}
+
+func (p *pendingWrite) bytes() []byte {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]byte{}, p.data...)
+}
+
+func (p *pendingWrite) writeAt(data []byte, offset int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	end := int(offset) + len(data)
+	if end > len(p.data) {
+		p.data = append(p.data, make([]byte, end-len(p.data))...)
+	}
+	copy(p.data[offset:], data)
+	p.dirty = true
+}
+
+func (p *pendingWrite) truncate(size uint64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if int(size) <= len(p.data) {
+		p.data = p.data[:size]
+	} else {
+		p.data = append(p.data, make([]byte, int(size)-len(p.data))...)
+	}
+	p.dirty = true
+}
+
+// writeKey identifies the write buffer of a file, views of the same node are buffered separately
+func (w WikiVFSFile) writeKey() string {
+	return w.path + "#" + w.view
+}
+
+func (vfs *WikiVFS) getPendingWrite(key string) *pendingWrite {
+	vfs.writesMutex.Lock()
+	defer vfs.writesMutex.Unlock()
+	return vfs.writes[key]
+}
+
+// acquirePendingWrite returns the write buffer for the file, seeded with its current content
+func (vfs *WikiVFS) acquirePendingWrite(w WikiVFSFile, truncate bool) (*pendingWrite, error) {
+	vfs.writesMutex.Lock()
+	defer vfs.writesMutex.Unlock()
+
+	pending, exists := vfs.writes[w.writeKey()]
+	if !exists {
+		pending = &pendingWrite{}
+		if !truncate {
+			content, err := w.content()
+			if err != nil {
+				return nil, err
+			}
+			pending.data = content
+		}
+		vfs.writes[w.writeKey()] = pending
+	}
+	if truncate {
+		pending.truncate(0)
+	}
+	pending.handles++
+	return pending, nil
+}
+
+func (vfs *WikiVFS) releasePendingWrite(key string) {
+	vfs.writesMutex.Lock()
+	defer vfs.writesMutex.Unlock()
+	pending, exists := vfs.writes[key]
+	if !exists {
+		return
+	}
+	pending.handles--
+	if pending.handles <= 0 {
+		delete(vfs.writes, key)
+	}
+}
+
+// commit writes the buffer to the real file, converting Markdown back to Syslang for wiki nodes
+func (w WikiVFSFile) commit() error {
+	pending := w.vfs.getPendingWrite(w.writeKey())
+	if pending == nil {
+		return nil
+	}
+
+	pending.mutex.Lock()
+	defer pending.mutex.Unlock()
+	if !pending.dirty {
+		return nil
+	}
+
+	data := pending.data
+	wikiNode, err := w.getWikiNode()
+	if err != nil {
+		return err
+	}
+	if wikiNode != nil && w.view == FILE_VIEW_MARKDOWN {
+		syslang, issues := markdown.ToSyslang(w.vfs.dialect().Revert(string(data)))
+		for _, issue := range issues {
+			log.Printf("%s:%s", w.path, issue)
+		}
+		data = []byte(syslang)
+	}
+
+	if err := corefs.WriteFileAtomic(w.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", w.path, err)
+	}
+	pending.dirty = false
+
+	w.vfs.cache.Evict(w.path)
+
+	return nil
+}
+
+func (w WikiVFSFile) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenResponse) (fs.Handle, error) {
+	if req.Flags.IsReadOnly() {
+		return w, nil
+	}
+	if !w.vfs.options.Writable || w.view == FILE_VIEW_HTML {
+		return nil, syscall.EROFS
+	}
+	if _, err := w.vfs.acquirePendingWrite(w, req.Flags&fuse.OpenTruncate != 0); err != nil {
+		return nil, err
+	}
+	// sizes change on write, don't let the kernel serve stale pages
+	resp.Flags |= fuse.OpenDirectIO
+	return w, nil
+}
+
+func (w WikiVFSFile) Write(ctx context.Context, req *fuse.WriteRequest, resp *fuse.WriteResponse) error {
+	pending := w.vfs.getPendingWrite(w.writeKey())
+	if pending == nil {
+		return syscall.EBADF
+	}
+	pending.writeAt(req.Data, req.Offset)
+	resp.Size = len(req.Data)
+	return nil
+}
+
+func (w WikiVFSFile) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fuse.SetattrResponse) error {
+	if req.Valid.Size() {
+		if !w.vfs.options.Writable || w.view == FILE_VIEW_HTML {
+			return syscall.EROFS
+		}
+		pending := w.vfs.getPendingWrite(w.writeKey())
+		if pending == nil {
+			// truncate(2) without an open handle
+			var err error
+			pending, err = w.vfs.acquirePendingWrite(w, false)
+			if err != nil {
+				return err
+			}
+			pending.truncate(req.Size)
+			err = w.commit()
+			w.vfs.releasePendingWrite(w.writeKey())
+			if err != nil {
+				return err
+			}
+		} else {
+			pending.truncate(req.Size)
+		}
+	}
+	return w.Attr(ctx, &resp.Attr)
+}
+
+func (w WikiVFSFile) Flush(ctx context.Context, req *fuse.FlushRequest) error {
+	return w.commit()
+}
+
+func (w WikiVFSFile) Fsync(ctx context.Context, req *fuse.FsyncRequest) error {
+	return w.commit()
+}
+
+func (w WikiVFSFile) Release(ctx context.Context, req *fuse.ReleaseRequest) error {
+	if req.ReleaseFlags&fuse.ReleaseFlush != 0 || !req.Flags.IsReadOnly() {
+		if err := w.commit(); err != nil {
+			return err
+		}
+	}
+	if !req.Flags.IsReadOnly() {
+		w.vfs.releasePendingWrite(w.writeKey())
+	}
+	return nil
+}
@@ -6,17 +6,22 @@ import (
 	"regexp"
 )
 
-var ignorePatterns = []string{
-	`\.`,
+var ignorePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\.`),
 }
 
-func WalkFiles(path string, filter *func(path string, info os.FileInfo) bool) ([]File, error) {
-	files := []File{}
-
-	patterns := []*regexp.Regexp{}
+// IsIgnored reports whether WalkFiles skips the file at path
+func IsIgnored(path string) bool {
 	for _, pattern := range ignorePatterns {
-		patterns = append(patterns, regexp.MustCompile(pattern))
+		if pattern.MatchString(path) {
+			return true
+		}
 	}
+	return false
+}
+
+func WalkFiles(path string, filter *func(path string, info os.FileInfo) bool) ([]File, error) {
+	files := []File{}
 
 	err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
 		// skip directories
@@ -25,10 +30,8 @@ func WalkFiles(path string, filter *func(path string, info os.FileInfo) bool) ([
 		}
 
 		// skip ignored files
-		for _, pattern := range patterns {
-			if pattern.MatchString(path) {
-				return nil
-			}
+		if IsIgnored(path) {
+			return nil
 		}
 
 		// apply filter
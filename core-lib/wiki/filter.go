@@ -0,0 +1,150 @@
+package wiki
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// comparison operators
+const (
+	OPERATOR_EQUAL         = "="
+	OPERATOR_NOT_EQUAL     = "!="
+	OPERATOR_CONTAINS      = "~"
+	OPERATOR_NOT_CONTAINS  = "!~"
+	OPERATOR_LESS          = "<"
+	OPERATOR_LESS_EQUAL    = "<="
+	OPERATOR_GREATER       = ">"
+	OPERATOR_GREATER_EQUAL = ">="
+)
+
+// And matches nodes matching every filter, no filters match every node
+func And(filters ...NodeFilter) NodeFilter {
+	return func(node Node) bool {
+		for _, filter := range filters {
+			if !filter(node) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches nodes matching any filter
+func Or(filters ...NodeFilter) NodeFilter {
+	return func(node Node) bool {
+		for _, filter := range filters {
+			if filter(node) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func Not(filter NodeFilter) NodeFilter {
+	return func(node Node) bool {
+		return !filter(node)
+	}
+}
+
+// MetaExists matches nodes with a non-empty meta key
+func MetaExists(key string) NodeFilter {
+	return func(node Node) bool {
+		_, ok := GetMetaValue(node, key)
+		return ok
+	}
+}
+
+// MetaIn matches nodes whose meta key equals one of values
+func MetaIn(key string, values ...string) NodeFilter {
+	return func(node Node) bool {
+		value, ok := GetMetaValue(node, key)
+		return ok && slices.Contains(values, value)
+	}
+}
+
+// MetaContains matches nodes whose meta key contains text, ignoring case
+func MetaContains(key string, text string) NodeFilter {
+	text = strings.ToLower(text)
+	return func(node Node) bool {
+		value, ok := GetMetaValue(node, key)
+		return ok && strings.Contains(strings.ToLower(value), text)
+	}
+}
+
+// compareMetaValues compares dates, then numbers, then strings
+func compareMetaValues(a string, b string) int {
+	if aDate, err := ParseMetaDate(a); err == nil {
+		if bDate, err := ParseMetaDate(b); err == nil {
+			return aDate.Compare(bDate)
+		}
+	}
+	if aNumber, err := strconv.ParseFloat(a, 64); err == nil {
+		if bNumber, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case aNumber < bNumber:
+				return -1
+			case aNumber > bNumber:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// MetaCompare matches nodes whose meta key compares to value with an ordering operator,
+// nodes without the key never match
+func MetaCompare(key string, operator string, value string) NodeFilter {
+	return func(node Node) bool {
+		current, ok := GetMetaValue(node, key)
+		if !ok {
+			return false
+		}
+		result := compareMetaValues(current, value)
+		switch operator {
+		case OPERATOR_LESS:
+			return result < 0
+		case OPERATOR_LESS_EQUAL:
+			return result <= 0
+		case OPERATOR_GREATER:
+			return result > 0
+		case OPERATOR_GREATER_EQUAL:
+			return result >= 0
+		}
+		return false
+	}
+}
+
+// ResolveMetaDate expands today, today+Nd and today-Nd (d, w) into a meta date, other values are
+// returned unchanged
+func ResolveMetaDate(value string, now time.Time) string {
+	if !strings.HasPrefix(value, "today") {
+		return value
+	}
+	offset := strings.TrimPrefix(value, "today")
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if offset != "" {
+		if len(offset) < 3 || (offset[0] != '+' && offset[0] != '-') {
+			return value
+		}
+		count, err := strconv.Atoi(offset[1 : len(offset)-1])
+		if err != nil {
+			return value
+		}
+		if offset[0] == '-' {
+			count = -count
+		}
+		switch offset[len(offset)-1] {
+		case 'd':
+			date = date.AddDate(0, 0, count)
+		case 'w':
+			date = date.AddDate(0, 0, count*7)
+		default:
+			return value
+		}
+	}
+	return date.Format(META_DATE_LAYOUT)
+}
@@ -0,0 +1,109 @@
+package wiki
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+type GraphEdge struct {
+	Source string
+	Target string
+}
+
+// Graph is the link graph of a wiki, edges reference node IDs.
+type Graph struct {
+	Nodes []Node
+	Edges []GraphEdge
+}
+
+// BuildBacklinks indexes the nodes linking to each node ID, links target node names and
+// unresolved links and self references are ignored
+func BuildBacklinks[T Node](nodes []T) map[string][]T {
+	ids := map[string]string{}
+	for _, node := range nodes {
+		ids[node.GetName()] = node.GetID()
+	}
+	backlinks := map[string][]T{}
+	for _, node := range nodes {
+		seen := map[string]bool{}
+		for _, link := range node.GetLinks() {
+			id, ok := ids[link.Target]
+			if !ok || seen[id] || id == node.GetID() {
+				continue
+			}
+			seen[id] = true
+			backlinks[id] = append(backlinks[id], node)
+		}
+	}
+	return backlinks
+}
+
+// NewGraph returns the nodes and the edges of their backlinks, sorted by source
+func NewGraph[T Node](nodes []T, backlinks map[string][]T) *Graph {
+	graph := Graph{Nodes: []Node{}, Edges: []GraphEdge{}}
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+		for _, source := range backlinks[node.GetID()] {
+			graph.Edges = append(graph.Edges, GraphEdge{Source: source.GetID(), Target: node.GetID()})
+		}
+	}
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		return graph.Edges[i].Source < graph.Edges[j].Source
+	})
+	return &graph
+}
+
+type JSONGraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+type JSONGraph struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Nodes         []JSONNode      `json:"nodes"`
+	Edges         []JSONGraphEdge `json:"edges"`
+}
+
+func NewJSONGraph(graph *Graph) JSONGraph {
+	result := JSONGraph{
+		SchemaVersion: JSON_SCHEMA_VERSION,
+		Nodes:         []JSONNode{},
+		Edges:         []JSONGraphEdge{},
+	}
+	for _, node := range graph.Nodes {
+		result.Nodes = append(result.Nodes, NewJSONNode(node))
+	}
+	for _, edge := range graph.Edges {
+		result.Edges = append(result.Edges, JSONGraphEdge{Source: edge.Source, Target: edge.Target})
+	}
+	return result
+}
+
+// WriteJSON writes the graph as an indented JSONGraph document.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewJSONGraph(g))
+}
+
+// WriteDOT writes the graph in Graphviz DOT format.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "digraph wiki {"); err != nil {
+		return err
+	}
+	for _, node := range g.Nodes {
+		if _, err := fmt.Fprintf(w, "  %s;\n", strconv.Quote(node.GetID())); err != nil {
+			return err
+		}
+	}
+	for _, edge := range g.Edges {
+		if _, err := fmt.Fprintf(w, "  %s -> %s;\n", strconv.Quote(edge.Source), strconv.Quote(edge.Target)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
@@ -0,0 +1,34 @@
+package wiki_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/3rd/core/core-lib/wiki"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph(t *testing.T) {
+	graph := &wiki.Graph{
+		Nodes: getTestNodes(t, map[string]string{"a": "", "b \"quoted\"": ""}),
+		Edges: []wiki.GraphEdge{{Source: "a", Target: "b \"quoted\""}},
+	}
+
+	t.Run("DOT", func(t *testing.T) {
+		buffer := bytes.Buffer{}
+		require.NoError(t, graph.WriteDOT(&buffer))
+		assert.Equal(t, "digraph wiki {\n  \"a\";\n  \"b \\\"quoted\\\"\";\n  \"a\" -> \"b \\\"quoted\\\"\";\n}\n", buffer.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		buffer := bytes.Buffer{}
+		require.NoError(t, graph.WriteJSON(&buffer))
+		var result wiki.JSONGraph
+		require.NoError(t, json.Unmarshal(buffer.Bytes(), &result))
+		assert.Equal(t, wiki.JSON_SCHEMA_VERSION, result.SchemaVersion)
+		assert.Len(t, result.Nodes, 2)
+		assert.Equal(t, []wiki.JSONGraphEdge{{Source: "a", Target: "b \"quoted\""}}, result.Edges)
+	})
+}
@@ -0,0 +1,65 @@
+package wiki
+
+import (
+	"strings"
+)
+
+const UTF8_BOM = "\uFEFF"
+
+// lines that may precede the meta block
+var headerCommentPrefixes = []string{"//", "#"}
+
+// IsHeaderPreamble reports whether a line may precede the meta block (blank lines and comments)
+func IsHeaderPreamble(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return true
+	}
+	for _, prefix := range headerCommentPrefixes {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindMetaBlock returns the line indexes of @meta and @end, ok is false when the lines don't start
+// with a meta block, ignoring a BOM, blank lines and comments before it
+func FindMetaBlock(lines []string) (int, int, bool) {
+	start := -1
+	for i, line := range lines {
+		if i == 0 {
+			line = strings.TrimPrefix(line, UTF8_BOM)
+		}
+		trimmed := strings.TrimSpace(line)
+		if start == -1 {
+			if trimmed == "@meta" {
+				start = i
+				continue
+			}
+			if !IsHeaderPreamble(line) {
+				return -1, -1, false
+			}
+			continue
+		}
+		if trimmed == "@end" {
+			return start, i, true
+		}
+	}
+	return -1, -1, false
+}
+
+// FindMetaKey returns the 1-based line and column of a key in the meta block, or the start of the node
+func FindMetaKey(lines []string, key string) (int, int) {
+	start, end, ok := FindMetaBlock(lines)
+	if !ok {
+		return 1, 1
+	}
+	for i := start + 1; i < end; i++ {
+		text := lines[i]
+		if strings.HasPrefix(strings.TrimSpace(text), key+":") {
+			return i + 1, len(text) - len(strings.TrimLeft(text, " \t")) + 1
+		}
+	}
+	return 1, 1
+}
@@ -0,0 +1,35 @@
+package wiki
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeader(t *testing.T) {
+	t.Run("FindMetaBlock", func(t *testing.T) {
+		start, end, ok := FindMetaBlock(strings.Split(UTF8_BOM+"// comment\n\n@meta\n  type: note\n@end\n* Title\n", "\n"))
+		assert.True(t, ok)
+		assert.Equal(t, 2, start)
+		assert.Equal(t, 4, end)
+
+		_, _, ok = FindMetaBlock(strings.Split("* Title\n@meta\n  type: note\n@end\n", "\n"))
+		assert.False(t, ok)
+	})
+
+	t.Run("FindMetaKey", func(t *testing.T) {
+		lines := strings.Split(UTF8_BOM+"# due: later\n@meta\n  type: note\n\tdue: 2024.01.01\n@end\ndue: body\n", "\n")
+		line, column := FindMetaKey(lines, "due")
+		assert.Equal(t, 4, line)
+		assert.Equal(t, 2, column)
+
+		line, column = FindMetaKey(lines, "status")
+		assert.Equal(t, 1, line)
+		assert.Equal(t, 1, column)
+
+		line, column = FindMetaKey(strings.Split("* Title\ndue: body\n", "\n"), "due")
+		assert.Equal(t, 1, line)
+		assert.Equal(t, 1, column)
+	})
+}
@@ -0,0 +1,168 @@
+package wiki
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// JSON_SCHEMA_VERSION is bumped on every breaking change to the JSON types below.
+const JSON_SCHEMA_VERSION = 1
+
+type JSONTaskSession struct {
+	Start      time.Time  `json:"start"`
+	End        *time.Time `json:"end,omitempty"`
+	Duration   int64      `json:"duration"`
+	LineNumber uint32     `json:"lineNumber"`
+}
+
+type JSONTaskSchedule struct {
+	Start      time.Time  `json:"start"`
+	End        *time.Time `json:"end,omitempty"`
+	Repeat     string     `json:"repeat,omitempty"`
+	LineNumber uint32     `json:"lineNumber"`
+}
+
+type JSONTaskCompletion struct {
+	Timestamp  time.Time `json:"timestamp"`
+	LineNumber uint32    `json:"lineNumber"`
+}
+
+type JSONTask struct {
+	NodeID           string               `json:"nodeId"`
+	NodeName         string               `json:"nodeName"`
+	Path             string               `json:"path,omitempty"`
+	Text             string               `json:"text"`
+	Status           string               `json:"status"`
+	Priority         uint32               `json:"priority"`
+	LineNumber       uint32               `json:"lineNumber"`
+	InProgress       bool                 `json:"inProgress"`
+	TotalSessionTime int64                `json:"totalSessionTime"`
+	Tags             []string             `json:"tags"`
+	Schedule         *JSONTaskSchedule    `json:"schedule,omitempty"`
+	Sessions         []JSONTaskSession    `json:"sessions"`
+	Completions      []JSONTaskCompletion `json:"completions"`
+}
+
+type JSONNode struct {
+	ID   string            `json:"id"`
+	Name string            `json:"name"`
+	Path string            `json:"path,omitempty"`
+	Meta map[string]string `json:"meta"`
+}
+
+// JSONNodeList is the document written by node list outputs.
+type JSONNodeList struct {
+	SchemaVersion int        `json:"schemaVersion"`
+	Nodes         []JSONNode `json:"nodes"`
+}
+
+// JSONRecord is a single line of JSONL output.
+type JSONRecord struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	Kind          string    `json:"kind"`
+	Group         string    `json:"group,omitempty"`
+	Task          *JSONTask `json:"task,omitempty"`
+	Node          *JSONNode `json:"node,omitempty"`
+}
+
+func NewJSONTask(task *Task) JSONTask {
+	result := JSONTask{
+		Text:             task.Text,
+		Status:           string(task.Status),
+		Priority:         task.Priority,
+		LineNumber:       task.LineNumber,
+		InProgress:       task.IsInProgress(),
+		TotalSessionTime: int64(task.GetTotalSessionTime().Seconds()),
+		Tags:             []string{},
+		Sessions:         []JSONTaskSession{},
+		Completions:      []JSONTaskCompletion{},
+	}
+	if task.Node != nil {
+		result.NodeID = task.Node.GetID()
+		result.NodeName = task.Node.GetName()
+		result.Path = task.Node.GetPath()
+	}
+	if task.Tags != nil {
+		result.Tags = task.Tags
+	}
+	if task.Schedule != nil {
+		result.Schedule = &JSONTaskSchedule{
+			Start:      task.Schedule.Start,
+			End:        task.Schedule.End,
+			Repeat:     task.Schedule.Repeat,
+			LineNumber: task.Schedule.LineNumber,
+		}
+	}
+	for _, session := range task.Sessions {
+		result.Sessions = append(result.Sessions, JSONTaskSession{
+			Start:      session.Start,
+			End:        session.End,
+			Duration:   int64(session.Duration().Seconds()),
+			LineNumber: session.LineNumber,
+		})
+	}
+	for _, completion := range task.Completions {
+		result.Completions = append(result.Completions, JSONTaskCompletion{
+			Timestamp:  completion.Timestamp,
+			LineNumber: completion.LineNumber,
+		})
+	}
+	return result
+}
+
+func NewJSONNode(node Node) JSONNode {
+	meta := node.GetMeta()
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	return JSONNode{
+		ID:   node.GetID(),
+		Name: node.GetName(),
+		Path: node.GetPath(),
+		Meta: meta,
+	}
+}
+
+func NewJSONNodeList[T Node](nodes []T) JSONNodeList {
+	result := JSONNodeList{
+		SchemaVersion: JSON_SCHEMA_VERSION,
+		Nodes:         []JSONNode{},
+	}
+	for _, node := range nodes {
+		result.Nodes = append(result.Nodes, NewJSONNode(node))
+	}
+	return result
+}
+
+// JSONLWriter streams one JSONRecord per line.
+type JSONLWriter struct {
+	encoder *json.Encoder
+}
+
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{encoder: json.NewEncoder(w)}
+}
+
+func (w *JSONLWriter) WriteTask(group string, task *Task) error {
+	return w.WriteJSONTask(group, NewJSONTask(task))
+}
+
+func (w *JSONLWriter) WriteJSONTask(group string, task JSONTask) error {
+	return w.encoder.Encode(JSONRecord{
+		SchemaVersion: JSON_SCHEMA_VERSION,
+		Kind:          "task",
+		Group:         group,
+		Task:          &task,
+	})
+}
+
+func (w *JSONLWriter) WriteNode(group string, node Node) error {
+	jsonNode := NewJSONNode(node)
+	return w.encoder.Encode(JSONRecord{
+		SchemaVersion: JSON_SCHEMA_VERSION,
+		Kind:          "node",
+		Group:         group,
+		Node:          &jsonNode,
+	})
+}
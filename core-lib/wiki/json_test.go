@@ -0,0 +1,97 @@
+package wiki_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/3rd/core/core-lib/wiki"
+	"github.com/3rd/core/core-lib/wiki/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getTestNodes builds memory nodes from contents keyed by path, the nodes are ordered by name
+func getTestNodes(t *testing.T, files map[string]string) []wiki.Node {
+	t.Helper()
+	memoryWiki, err := memory.NewMemoryWiki(files)
+	require.NoError(t, err)
+	nodes, err := memoryWiki.GetNodes()
+	require.NoError(t, err)
+	return nodes
+}
+
+func getTestNode(t *testing.T, path string, content string) wiki.Node {
+	t.Helper()
+	return getTestNodes(t, map[string]string{path: content})[0]
+}
+
+func TestJSON(t *testing.T) {
+	node := getTestNode(t, "project-a", "@meta\ntype: project\n@end\n")
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	task := &wiki.Task{
+		Node:        node,
+		Text:        "task",
+		Status:      wiki.TASK_STATUS_DONE,
+		Priority:    3,
+		LineNumber:  4,
+		Sessions:    []wiki.TaskSession{{Start: start, End: &end, LineNumber: 5}},
+		Schedule:    &wiki.TaskSchedule{Start: start, Repeat: "daily", LineNumber: 6},
+		Completions: []wiki.TaskCompletion{{Timestamp: end, LineNumber: 7}},
+	}
+
+	t.Run("NewJSONTask", func(t *testing.T) {
+		result := wiki.NewJSONTask(task)
+		assert.Equal(t, "project-a", result.NodeID)
+		assert.Equal(t, "project-a", result.Path)
+		assert.Equal(t, "done", result.Status)
+		assert.Equal(t, uint32(3), result.Priority)
+		assert.Equal(t, int64(3600), result.TotalSessionTime)
+		assert.Len(t, result.Sessions, 1)
+		assert.Equal(t, uint32(5), result.Sessions[0].LineNumber)
+		assert.Equal(t, "daily", result.Schedule.Repeat)
+		assert.Len(t, result.Completions, 1)
+		assert.Equal(t, []string{}, result.Tags)
+	})
+
+	t.Run("NewJSONNode", func(t *testing.T) {
+		result := wiki.NewJSONNode(getTestNode(t, "empty", ""))
+		assert.Equal(t, "empty", result.ID)
+		assert.Equal(t, map[string]string{}, result.Meta)
+	})
+
+	t.Run("NewJSONNodeList", func(t *testing.T) {
+		result := wiki.NewJSONNodeList([]wiki.Node{node})
+		assert.Equal(t, wiki.JSON_SCHEMA_VERSION, result.SchemaVersion)
+		require.Len(t, result.Nodes, 1)
+		assert.Equal(t, "project-a", result.Nodes[0].ID)
+
+		data, err := json.Marshal(wiki.NewJSONNodeList([]wiki.Node{}))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"schemaVersion":1,"nodes":[]}`, string(data))
+	})
+
+	t.Run("JSONLWriter", func(t *testing.T) {
+		buffer := bytes.Buffer{}
+		writer := wiki.NewJSONLWriter(&buffer)
+		require.NoError(t, writer.WriteTask("active", task))
+		require.NoError(t, writer.WriteNode("", node))
+
+		lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+		require.Len(t, lines, 2)
+
+		record := wiki.JSONRecord{}
+		require.NoError(t, json.Unmarshal(lines[0], &record))
+		assert.Equal(t, wiki.JSON_SCHEMA_VERSION, record.SchemaVersion)
+		assert.Equal(t, "task", record.Kind)
+		assert.Equal(t, "active", record.Group)
+		assert.Equal(t, "task", record.Task.Text)
+
+		record = wiki.JSONRecord{}
+		require.NoError(t, json.Unmarshal(lines[1], &record))
+		assert.Equal(t, "node", record.Kind)
+		assert.Equal(t, "project", record.Node.Meta["type"])
+	})
+}
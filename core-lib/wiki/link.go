@@ -0,0 +1,79 @@
+package wiki
+
+import (
+	"regexp"
+	"strings"
+)
+
+var linkRe = regexp.MustCompile(`\[\[([^\[\]|]+)(?:\|([^\[\]]+))?\]\]`)
+
+// Link is an outgoing [[target]] or [[target|label]] reference, target is a node name.
+type Link struct {
+	Target     string
+	Label      string
+	LineNumber uint32
+	Column     uint32
+}
+
+// ExtractLinks returns the links of a Syslang document, skipping @code blocks.
+func ExtractLinks(text string) []Link {
+	links := []Link{}
+	inCode := false
+	for i, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if inCode {
+			if trimmed == "@end" {
+				inCode = false
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "@code") {
+			inCode = true
+			continue
+		}
+		for _, match := range linkRe.FindAllStringSubmatchIndex(line, -1) {
+			link := Link{
+				Target:     strings.TrimSpace(line[match[2]:match[3]]),
+				LineNumber: uint32(i),
+				Column:     uint32(match[0]),
+			}
+			if match[4] != -1 {
+				link.Label = line[match[4]:match[5]]
+			}
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// RewriteLinks points the links to from at to, keeping labels, and returns the number of rewritten links.
+func RewriteLinks(text string, from string, to string) (string, int) {
+	count := 0
+	inCode := false
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if inCode {
+			if trimmed == "@end" {
+				inCode = false
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "@code") {
+			inCode = true
+			continue
+		}
+		lines[i] = linkRe.ReplaceAllStringFunc(line, func(match string) string {
+			parts := linkRe.FindStringSubmatch(match)
+			if strings.TrimSpace(parts[1]) != from {
+				return match
+			}
+			count++
+			if parts[2] != "" {
+				return "[[" + to + "|" + parts[2] + "]]"
+			}
+			return "[[" + to + "]]"
+		})
+	}
+	return strings.Join(lines, "\n"), count
+}
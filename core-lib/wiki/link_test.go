@@ -0,0 +1,29 @@
+package wiki
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractLinks(t *testing.T) {
+	t.Run("Targets, labels and positions", func(t *testing.T) {
+		links := ExtractLinks("* Title\n  See [[Project A]] and [[Project B|b]].\n")
+		assert.Equal(t, []Link{
+			{Target: "Project A", LineNumber: 1, Column: 6},
+			{Target: "Project B", Label: "b", LineNumber: 1, Column: 24},
+		}, links)
+	})
+
+	t.Run("Skip code blocks", func(t *testing.T) {
+		links := ExtractLinks("@code sh\n  echo [[Hidden]]\n@end\n[[Visible]]\n")
+		assert.Len(t, links, 1)
+		assert.Equal(t, "Visible", links[0].Target)
+	})
+}
+
+func TestRewriteLinks(t *testing.T) {
+	text, count := RewriteLinks("[[Old]] [[Old|label]] [[ Old ]] [[Other]]\n@code\n  [[Old]]\n@end\n", "Old", "New")
+	assert.Equal(t, 3, count)
+	assert.Equal(t, "[[New]] [[New|label]] [[New]] [[Other]]\n@code\n  [[Old]]\n@end\n", text)
+}
@@ -0,0 +1,203 @@
+// Package lint reports broken references and malformed content in a wiki.
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/3rd/core/core-lib/wiki"
+)
+
+// rules
+const (
+	RULE_BROKEN_LINK   = "broken-link"
+	RULE_ORPHAN        = "orphan"
+	RULE_META_VALUE    = "meta-value"
+	RULE_TASK_PROPERTY = "task-property"
+)
+
+var Rules = []string{RULE_BROKEN_LINK, RULE_ORPHAN, RULE_META_VALUE, RULE_TASK_PROPERTY}
+
+var taskPropertyRe = regexp.MustCompile(`^(\s*)(Session|Schedule|Done):`)
+
+// Config selects the rules to run, it's read from CONFIG_FILE at the wiki root when present
+type Config struct {
+	// Disabled lists rules that are skipped
+	Disabled []string `json:"disabled"`
+	// MetaValues lists the allowed values of meta keys, keys not listed accept anything
+	MetaValues map[string][]string `json:"metaValues"`
+}
+
+const CONFIG_FILE = ".lint.json"
+
+// LoadConfig reads a config file, a missing file yields the default config
+func LoadConfig(path string) (*Config, error) {
+	config := Config{Disabled: []string{}, MetaValues: map[string][]string{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid lint config %s: %w", path, err)
+	}
+	for _, rule := range config.Disabled {
+		if !slices.Contains(Rules, rule) {
+			return nil, fmt.Errorf("invalid lint rule: %s", rule)
+		}
+	}
+	return &config, nil
+}
+
+func (c *Config) IsEnabled(rule string) bool {
+	return !slices.Contains(c.Disabled, rule)
+}
+
+// Issue is a lint finding, Line and Column are 1-based
+type Issue struct {
+	Path    string
+	Line    int
+	Column  int
+	Rule    string
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", i.Path, i.Line, i.Column, i.Rule, i.Message)
+}
+
+func lintLinks(wikiInstance wiki.Wiki, root string, node wiki.Node) ([]Issue, error) {
+	issues := []Issue{}
+	for _, link := range node.GetLinks() {
+		target, err := wikiInstance.GetNodeByName(link.Target)
+		if err != nil {
+			return nil, err
+		}
+		if target != nil {
+			continue
+		}
+		issues = append(issues, Issue{
+			Path:    node.GetPath(),
+			Line:    int(link.LineNumber) + 1,
+			Column:  int(link.Column) + 1,
+			Rule:    RULE_BROKEN_LINK,
+			Message: fmt.Sprintf("unknown node %q (resolves to %s)", link.Target, filepath.Join(root, "unsorted", link.Target)),
+		})
+	}
+	return issues, nil
+}
+
+func lintMetaValues(config *Config, node wiki.Node, lines []string) []Issue {
+	issues := []Issue{}
+	meta := node.GetMeta()
+	for key, allowed := range config.MetaValues {
+		value, ok := meta[key]
+		if !ok || slices.Contains(allowed, value) {
+			continue
+		}
+		line, column := wiki.FindMetaKey(lines, key)
+		issues = append(issues, Issue{
+			Path:    node.GetPath(),
+			Line:    line,
+			Column:  column,
+			Rule:    RULE_META_VALUE,
+			Message: fmt.Sprintf("unknown %s %q (expected one of %s)", key, value, strings.Join(allowed, ", ")),
+		})
+	}
+	return issues
+}
+
+// lintTaskProperties reports property lines the parser didn't attach to a task
+func lintTaskProperties(node wiki.Node, lines []string) []Issue {
+	parsed := map[uint32]bool{}
+	for _, task := range node.GetTasks() {
+		for _, session := range task.Sessions {
+			parsed[session.LineNumber] = true
+		}
+		if task.Schedule != nil {
+			parsed[task.Schedule.LineNumber] = true
+		}
+		for _, completion := range task.Completions {
+			parsed[completion.LineNumber] = true
+		}
+	}
+
+	issues := []Issue{}
+	for i, line := range lines {
+		match := taskPropertyRe.FindStringSubmatch(line)
+		if match == nil || parsed[uint32(i)] {
+			continue
+		}
+		issues = append(issues, Issue{
+			Path:    node.GetPath(),
+			Line:    i + 1,
+			Column:  len(match[1]) + 1,
+			Rule:    RULE_TASK_PROPERTY,
+			Message: fmt.Sprintf("malformed %s line %q", match[2], strings.TrimSpace(line)),
+		})
+	}
+	return issues
+}
+
+// Lint runs the enabled rules over every node, issues are sorted by position
+func Lint(wikiInstance wiki.Wiki, root string, config *Config) ([]Issue, error) {
+	nodes, err := wikiInstance.GetNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	issues := []Issue{}
+	for _, node := range nodes {
+		text, err := node.GetContent()
+		if err != nil {
+			return nil, err
+		}
+		lines := strings.Split(text, "\n")
+
+		if config.IsEnabled(RULE_BROKEN_LINK) {
+			linkIssues, err := lintLinks(wikiInstance, root, node)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, linkIssues...)
+		}
+		if config.IsEnabled(RULE_META_VALUE) {
+			issues = append(issues, lintMetaValues(config, node, lines)...)
+		}
+		if config.IsEnabled(RULE_TASK_PROPERTY) {
+			issues = append(issues, lintTaskProperties(node, lines)...)
+		}
+	}
+
+	if config.IsEnabled(RULE_ORPHAN) {
+		for _, node := range nodes {
+			backlinks, err := wikiInstance.GetBacklinks(node.GetID())
+			if err != nil {
+				return nil, err
+			}
+			if len(backlinks) > 0 {
+				continue
+			}
+			issues = append(issues, Issue{Path: node.GetPath(), Line: 1, Column: 1, Rule: RULE_ORPHAN, Message: "node is never referenced"})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Path != issues[j].Path {
+			return issues[i].Path < issues[j].Path
+		}
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Column < issues[j].Column
+	})
+	return issues, nil
+}
@@ -0,0 +1,87 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/3rd/core/core-lib/wiki/local"
+	"github.com/3rd/core/core-lib/wiki/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	root, err := filepath.Abs("../../test-data/wiki/lint")
+	require.NoError(t, err)
+	wikiInstance, err := local.NewLocalWiki(local.LocalWikiConfig{Root: root, Parse: local.PARSE_MODE_FULL})
+	require.NoError(t, err)
+	projectPath := filepath.Join(root, "project-a")
+
+	t.Run("Report all rules", func(t *testing.T) {
+		config := &Config{MetaValues: map[string][]string{"type": {"project", "note"}}}
+		issues, err := Lint(wikiInstance, root, config)
+		require.NoError(t, err)
+
+		lines := []string{}
+		for _, issue := range issues {
+			lines = append(lines, issue.String())
+		}
+		assert.Equal(t, []string{
+			projectPath + `:3:3: meta-value: unknown type "projekt" (expected one of project, note)`,
+			projectPath + `:9:5: task-property: malformed Session line "Session: yesterday"`,
+			projectPath + `:10:20: broken-link: unknown node "Missing" (resolves to ` + filepath.Join(root, "unsorted", "Missing") + `)`,
+		}, lines)
+	})
+
+	t.Run("Report orphans", func(t *testing.T) {
+		orphanRoot := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(orphanRoot, "alone"), []byte("* Alone\n"), 0o644))
+		orphanWiki, err := local.NewLocalWiki(local.LocalWikiConfig{Root: orphanRoot, Parse: local.PARSE_MODE_FULL})
+		require.NoError(t, err)
+
+		issues, err := Lint(orphanWiki, orphanRoot, &Config{})
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, RULE_ORPHAN, issues[0].Rule)
+		assert.Equal(t, 1, issues[0].Line)
+	})
+
+	t.Run("Lint a memory wiki", func(t *testing.T) {
+		memoryWiki, err := memory.NewMemoryWiki(map[string]string{
+			"alpha": "* Alpha\n  See [[beta]] and [[gamma]].\n",
+			"beta":  "* Beta\n  See [[alpha]].\n",
+		})
+		require.NoError(t, err)
+
+		issues, err := Lint(memoryWiki, "", &Config{})
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, RULE_BROKEN_LINK, issues[0].Rule)
+		assert.Equal(t, "alpha", issues[0].Path)
+	})
+
+	t.Run("Disable rules", func(t *testing.T) {
+		config := &Config{Disabled: Rules}
+		issues, err := Lint(wikiInstance, root, config)
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("Load config", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), CONFIG_FILE)
+		config, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.True(t, config.IsEnabled(RULE_ORPHAN))
+
+		require.NoError(t, os.WriteFile(path, []byte(`{"disabled": ["orphan"], "metaValues": {"type": ["project"]}}`), 0o644))
+		config, err = LoadConfig(path)
+		require.NoError(t, err)
+		assert.False(t, config.IsEnabled(RULE_ORPHAN))
+		assert.Equal(t, []string{"project"}, config.MetaValues["type"])
+
+		require.NoError(t, os.WriteFile(path, []byte(`{"disabled": ["nope"]}`), 0o644))
+		_, err = LoadConfig(path)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,77 @@
+package local
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/3rd/core/core-lib/wiki"
+)
+
+// MAX_META_HEADER_SIZE bounds how much of a file is read looking for the end of its meta block
+const MAX_META_HEADER_SIZE = 64 * 1024
+
+// normalizeHeader moves a meta block preceded by a BOM, blank lines or comments to the top so full parses
+// read the same meta as readMetaHeader, the preamble is blanked after the block to keep line numbers
+func normalizeHeader(text string) string {
+	lines := strings.Split(text, "\n")
+	lines[0] = strings.TrimPrefix(lines[0], wiki.UTF8_BOM)
+	start, end, ok := wiki.FindMetaBlock(lines)
+	if !ok || start == 0 {
+		return strings.Join(lines, "\n")
+	}
+	result := make([]string, 0, len(lines))
+	result = append(result, lines[start:end+1]...)
+	result = append(result, make([]string, start)...)
+	result = append(result, lines[end+1:]...)
+	return strings.Join(result, "\n")
+}
+
+// readMetaHeader streams a file up to the end of its meta block and returns the block alone,
+// files without a meta block yield an empty header
+func readMetaHeader(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return scanMetaHeader(file)
+}
+
+// scanMetaHeader is readMetaHeader on a reader, nothing past @end is read
+func scanMetaHeader(source io.Reader) (string, error) {
+	reader := bufio.NewReader(io.LimitReader(source, MAX_META_HEADER_SIZE))
+	lines := []string{}
+	inMeta := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if len(lines) == 0 && !inMeta {
+			line = strings.TrimPrefix(line, wiki.UTF8_BOM)
+		}
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case !inMeta && trimmed == "@meta":
+			inMeta = true
+			lines = append(lines, "@meta")
+		case !inMeta && wiki.IsHeaderPreamble(line):
+			// skipped
+		case !inMeta:
+			return "", nil
+		case trimmed == "@end":
+			return strings.Join(append(lines, "@end"), "\n") + "\n", nil
+		default:
+			lines = append(lines, line)
+		}
+
+		if errors.Is(err, io.EOF) {
+			return "", nil
+		}
+	}
+}
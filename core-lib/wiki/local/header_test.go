@@ -0,0 +1,88 @@
+package local
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMetaHeader(t *testing.T) {
+	header := "@meta\n  title: Alpha\n@end\n"
+	cases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"Plain", "@meta\n  title: Alpha\n@end\n\n* Alpha\n", header},
+		{"BOM", "\uFEFF@meta\n  title: Alpha\n@end\n", header},
+		{"Leading blank lines and comments", "\n  \n// draft\n# note\n@meta\n  title: Alpha\n@end\n", header},
+		{"CRLF", "@meta\r\n  title: Alpha\r\n@end\r\n* Alpha\r\n", header},
+		{"No meta", "* Alpha\n@meta\n  title: Alpha\n@end\n", ""},
+		{"Unterminated meta", "@meta\n  title: Alpha\n", ""},
+		{"Empty", "", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "node")
+			require.NoError(t, os.WriteFile(path, []byte(c.content), 0o644))
+			result, err := readMetaHeader(path)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, result)
+		})
+	}
+
+	t.Run("Stop at the end of the meta block", func(t *testing.T) {
+		// any read past @end fails
+		reader := io.MultiReader(strings.NewReader(header), iotest.ErrReader(errors.New("read past @end")))
+		result, err := scanMetaHeader(reader)
+		require.NoError(t, err)
+		assert.Equal(t, header, result)
+	})
+
+	t.Run("Normalize the header for full parses", func(t *testing.T) {
+		assert.Equal(t, "@meta\n  title: Alpha\n@end\n\n\n* Alpha\n", normalizeHeader("\uFEFF\n// draft\n@meta\n  title: Alpha\n@end\n* Alpha\n"))
+		assert.Equal(t, "@meta\n@end\n", normalizeHeader("\uFEFF@meta\n@end\n"))
+		assert.Equal(t, "* Alpha\n@meta\n@end\n", normalizeHeader("* Alpha\n@meta\n@end\n"))
+	})
+
+	t.Run("Keep the name and id when a meta parse is upgraded", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "node")
+		content := "\uFEFF// draft\n# note\n\n@meta\n  title: Alpha\n  id: alpha-id\n@end\n\n* Tasks\n  [ ] task\n"
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		node, err := NewLocalNode(path)
+		require.NoError(t, err)
+		require.NoError(t, node.Parse(PARSE_MODE_META))
+		assert.Equal(t, "Alpha", node.GetName())
+		assert.Equal(t, "alpha-id", node.GetID())
+
+		tasks := node.GetTasks()
+		assert.Equal(t, PARSE_MODE_FULL, node.GetParseMode())
+		assert.Equal(t, "Alpha", node.GetName())
+		assert.Equal(t, "alpha-id", node.GetID())
+		// task lines still point into the file
+		require.Len(t, tasks, 1)
+		assert.Equal(t, uint32(9), tasks[0].LineNumber)
+		assert.Equal(t, "  [ ] task", strings.Split(content, "\n")[tasks[0].LineNumber])
+	})
+
+	t.Run("Resolve titles through the header", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "node")
+		require.NoError(t, os.WriteFile(path, []byte("\uFEFF\n// draft\n@meta\n  title: Alpha\n@end\n"), 0o644))
+		node, err := NewLocalNode(path)
+		require.NoError(t, err)
+		require.NoError(t, node.Parse(PARSE_MODE_META))
+		assert.Equal(t, "Alpha", node.GetName())
+	})
+
+	t.Run("Set meta values after a preamble", func(t *testing.T) {
+		text := setMetaValue("\n// draft\n@meta\n  title: Alpha\n@end\n", "id", "alpha")
+		assert.Equal(t, "\n// draft\n@meta\n  title: Alpha\n  id: alpha\n@end\n", text)
+	})
+}
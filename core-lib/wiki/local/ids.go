@@ -0,0 +1,35 @@
+package local
+
+import (
+	"github.com/3rd/core/core-lib/fs"
+	"github.com/3rd/core/core-lib/wiki"
+)
+
+// StampIDs writes the current ID of every node without an id meta key into its meta block, so
+// renames and moves keep their identity, in dry-run mode nothing is written
+func (w *LocalWiki) StampIDs(dryRun bool) ([]*LocalNode, error) {
+	stamped := []*LocalNode{}
+	for _, node := range w.getNodes() {
+		if err := node.EnsureParsed(PARSE_MODE_META); err != nil {
+			return nil, err
+		}
+		if node.GetMeta()[wiki.ID_META_KEY] != "" {
+			continue
+		}
+		stamped = append(stamped, node)
+		if dryRun {
+			continue
+		}
+		text, err := node.Text()
+		if err != nil {
+			return nil, err
+		}
+		if err := fs.WriteFileAtomic(node.GetPath(), []byte(setMetaValue(text, wiki.ID_META_KEY, node.GetID())), 0o644); err != nil {
+			return nil, err
+		}
+	}
+	if dryRun || len(stamped) == 0 {
+		return stamped, nil
+	}
+	return stamped, w.Reload()
+}
@@ -1,20 +1,28 @@
 package local
 
 import (
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/3rd/core/core-lib/fs"
 	"github.com/3rd/core/core-lib/wiki"
+	wiki_syslang "github.com/3rd/core/core-lib/wiki/syslang"
 	"github.com/3rd/syslang/go-syslang/pkg/syslang"
 )
 
+// LocalNode is a wiki node backed by a file, it's parsed up to the level its users need,
+// parse state is guarded by mutex
 type LocalNode struct {
 	fs.File
+	mutex         sync.Mutex
+	root          string
 	document      *syslang.Document
 	parsedMode    PARSE_MODE
 	cachedName    *string
 	cachedTasks   *[]syslang.Task
+	cachedLinks   *[]wiki.Link
 	ParseDuration time.Duration
 }
 
@@ -25,25 +33,44 @@ func NewLocalNode(path string) (*LocalNode, error) {
 	}
 
 	node := LocalNode{
-		*file,
-		nil,
-		PARSE_MODE_NONE,
-		nil,
-		nil,
-		0,
+		File:       *file,
+		parsedMode: PARSE_MODE_NONE,
 	}
 	return &node, nil
 }
 
+// GetID returns the id meta value, falling back to the path relative to the wiki root,
+// titles are display names only, unparsed nodes read their meta first so the id doesn't depend on parse state
 func (n *LocalNode) GetID() string {
-	return n.GetName()
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if err := n.ensureParsed(PARSE_MODE_META); err == nil {
+		if id := strings.TrimSpace(n.document.GetMeta()[wiki.ID_META_KEY]); id != "" {
+			return id
+		}
+	}
+	return n.GetRelativePath()
+}
+
+// GetRelativePath returns the slash separated path of the node relative to the wiki root,
+// or the file name for nodes loaded outside a wiki
+func (n *LocalNode) GetRelativePath() string {
+	if n.root != "" {
+		if relativePath, err := filepath.Rel(n.root, n.GetPath()); err == nil {
+			return filepath.ToSlash(relativePath)
+		}
+	}
+	return n.File.GetName()
 }
 
+// GetName returns the title, falling back to the file name, unparsed nodes read their meta first
 func (n *LocalNode) GetName() string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
 	if n.cachedName != nil {
 		return *n.cachedName
 	}
-	if n.document != nil {
+	if err := n.ensureParsed(PARSE_MODE_META); err == nil {
 		title := n.document.GetTitle()
 		if title == "" {
 			title = n.File.GetName()
@@ -54,135 +81,166 @@ func (n *LocalNode) GetName() string {
 	return n.File.GetName()
 }
 
+// GetMeta returns the meta block, unparsed nodes read it first
 func (n *LocalNode) GetMeta() map[string]string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if err := n.ensureParsed(PARSE_MODE_META); err != nil {
+		return map[string]string{}
+	}
 	return n.document.GetMeta()
 }
 
+// GetMetaValue returns a trimmed meta value and whether it's set
+func (n *LocalNode) GetMetaValue(key string) (string, bool) {
+	return wiki.GetMetaValue(n, key)
+}
+
+// GetMetaList returns a comma separated meta value as a list
+func (n *LocalNode) GetMetaList(key string) []string {
+	value, _ := n.GetMetaValue(key)
+	return wiki.ParseMetaList(value)
+}
+
+// GetMetaDate returns a meta date, ok is false when it's missing or invalid
+func (n *LocalNode) GetMetaDate(key string) (time.Time, bool) {
+	value, ok := n.GetMetaValue(key)
+	if !ok {
+		return time.Time{}, false
+	}
+	date, err := wiki.ParseMetaDate(value)
+	return date, err == nil
+}
+
 func (n *LocalNode) GetContent() (string, error) {
 	return n.Text()
 }
 
 func (n *LocalNode) IsParsed() bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
 	return n.document != nil
 }
 
+// GetParseMode returns the level the node is parsed at
+func (n *LocalNode) GetParseMode() PARSE_MODE {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.parsedMode
+}
+
+// covers reports whether content parsed at mode includes what's parsed at other
+func (mode PARSE_MODE) covers(other PARSE_MODE) bool {
+	switch other {
+	case PARSE_MODE_NONE:
+		return true
+	case PARSE_MODE_META:
+		return mode == PARSE_MODE_META || mode == PARSE_MODE_FULL
+	}
+	return mode == PARSE_MODE_FULL
+}
+
+// Parse (re)parses the node at mode, use EnsureParsed to only upgrade when needed
 func (n *LocalNode) Parse(mode PARSE_MODE) error {
 	if mode == PARSE_MODE_NONE {
 		panic("cannot parse with PARSE_MODE_NONE, you have a bug")
 	}
-	n.parsedMode = mode
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.parse(mode)
+}
 
-	text, err := n.Text()
-	if err != nil {
-		return err
+// EnsureParsed parses the node if it's not parsed at mode or a higher level yet
+func (n *LocalNode) EnsureParsed(mode PARSE_MODE) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.ensureParsed(mode)
+}
+
+func (n *LocalNode) ensureParsed(mode PARSE_MODE) error {
+	if n.document != nil && n.parsedMode.covers(mode) {
+		return nil
 	}
+	return n.parse(mode)
+}
 
+func (n *LocalNode) parse(mode PARSE_MODE) error {
+	var text string
+	var err error
 	if mode == PARSE_MODE_META {
-		hasMeta := true
-		if !strings.HasPrefix(text, "@meta") {
-			hasMeta = false
-		}
-		endIndex := strings.Index(text, "@end")
-		if endIndex == -1 {
-			hasMeta = false
-		}
-		if !hasMeta {
-			n.document, err = syslang.NewDocument("")
-			if err != nil {
-				return err
-			}
-			return nil
-		}
-
-		text = text[:endIndex+len("@end")]
+		text, err = readMetaHeader(n.GetPath())
+	} else {
+		text, err = n.Text()
+		text = normalizeHeader(text)
+	}
+	if err != nil {
+		return err
 	}
 
 	start := time.Now()
-	n.document, err = syslang.NewDocument(text)
+	document, err := syslang.NewDocument(text)
 	if err != nil {
 		return err
 	}
+	n.document = document
+	n.parsedMode = mode
 	n.ParseDuration = time.Since(start)
 
 	n.cachedName = nil
 	n.cachedTasks = nil
+	n.cachedLinks = nil
 
 	return nil
 }
 
 func (n *LocalNode) Refresh() error {
-	if !n.IsParsed() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.document == nil {
 		return nil
 	}
-	return n.Parse(n.parsedMode)
+	return n.parse(n.parsedMode)
 }
 
 func (n *LocalNode) GetTasks() []*wiki.Task {
-	tasks := []*wiki.Task{}
-
-	var syslangTasks []syslang.Task
-	if n.cachedTasks != nil {
-		syslangTasks = *n.cachedTasks
-	} else {
-		syslangTasks = n.document.GetTasks()
+	// tasks need a full parse, nodes loaded with meta only are upgraded here
+	n.mutex.Lock()
+	if err := n.ensureParsed(PARSE_MODE_FULL); err != nil {
+		n.mutex.Unlock()
+		return []*wiki.Task{}
+	}
+	if n.cachedTasks == nil {
+		syslangTasks := n.document.GetTasks()
 		n.cachedTasks = &syslangTasks
 	}
+	syslangTasks := *n.cachedTasks
+	n.mutex.Unlock()
 
-	for _, syslangTask := range syslangTasks {
-		sessions := []wiki.TaskSession{}
-		for _, session := range syslangTask.Sessions {
-			sessions = append(sessions, wiki.TaskSession{
-				Start:      session.Start,
-				End:        session.End,
-				LineNumber: session.Line,
-			})
-		}
-
-		var schedule *wiki.TaskSchedule
-		if syslangTask.Schedule != nil {
-			schedule = &wiki.TaskSchedule{
-				Start:      syslangTask.Schedule.Start,
-				End:        syslangTask.Schedule.End,
-				Repeat:     syslangTask.Schedule.Repeat,
-				LineNumber: syslangTask.Schedule.Line,
-			}
-		}
-
-		completions := []wiki.TaskCompletion{}
-		for _, completion := range syslangTask.Completions {
-			completions = append(completions, wiki.TaskCompletion{
-				Timestamp:  completion.Start,
-				LineNumber: completion.Line,
-			})
-		}
+	return wiki_syslang.NewTasks(n, syslangTasks)
+}
 
-		task := &wiki.Task{
-			Node:        n,
-			Parent:      nil,
-			Children:    []*wiki.Task{},
-			Sessions:    sessions,
-			Schedule:    schedule,
-			Text:        syslangTask.Title,
-			LineNumber:  syslangTask.Line,
-			LineText:    syslangTask.LineText,
-			Status:      wiki.TASK_STATUS_DEFAULT,
-			Completions: completions,
-			Priority:    syslangTask.Priority,
-		}
-		if syslangTask.Status == syslang.TaskStatusActive {
-			task.Status = wiki.TASK_STATUS_ACTIVE
-		}
-		if syslangTask.Status == syslang.TaskStatusDone {
-			task.Status = wiki.TASK_STATUS_DONE
-		}
-		if syslangTask.Status == syslang.TaskStatusCancelled {
-			task.Status = wiki.TASK_STATUS_CANCELLED
-		}
-		tasks = append(tasks, task)
+// GetLinks returns the outgoing links of the node, read from the source so it works in any parse mode
+func (n *LocalNode) GetLinks() []wiki.Link {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.cachedLinks != nil {
+		return *n.cachedLinks
+	}
+	text, err := n.Text()
+	if err != nil {
+		return []wiki.Link{}
 	}
-	return tasks
+	links := wiki.ExtractLinks(text)
+	n.cachedLinks = &links
+	return links
 }
 
+// ToMarkdown converts the fully parsed node, upgrading meta only parses
 func (n *LocalNode) ToMarkdown() string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if err := n.ensureParsed(PARSE_MODE_FULL); err != nil {
+		return ""
+	}
 	return n.document.ToMarkdown()
 }
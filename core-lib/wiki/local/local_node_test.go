@@ -1,8 +1,10 @@
 package local
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/3rd/core/core-lib/wiki"
 	"github.com/stretchr/testify/assert"
@@ -16,10 +18,10 @@ func TestLocalNode(t *testing.T) {
 
 		node, err := NewLocalNode(path)
 		assert.NoError(t, err)
+		assert.False(t, node.IsParsed())
 		assert.Equal(t, "root-1", node.GetID())
 		assert.Equal(t, "root-1", node.GetName())
 		assert.Equal(t, path, node.GetPath())
-		assert.False(t, node.IsParsed())
 
 		content, err := node.GetContent()
 		assert.NoError(t, err)
@@ -52,6 +54,67 @@ func TestLocalNode(t *testing.T) {
 		assert.Equal(t, "Custom title", node.GetName())
 	})
 
+	t.Run("Upgrade meta parses on demand", func(t *testing.T) {
+		path, err := filepath.Abs("../../test-data/wiki/tasks/sample")
+		require.NoError(t, err)
+
+		node, err := NewLocalNode(path)
+		require.NoError(t, err)
+		assert.Equal(t, PARSE_MODE_NONE, node.GetParseMode())
+
+		require.NoError(t, node.Parse(PARSE_MODE_META))
+		assert.Equal(t, PARSE_MODE_META, node.GetParseMode())
+		require.NoError(t, node.EnsureParsed(PARSE_MODE_META))
+		assert.Equal(t, PARSE_MODE_META, node.GetParseMode())
+
+		assert.Len(t, node.GetTasks(), 4)
+		assert.Equal(t, PARSE_MODE_FULL, node.GetParseMode())
+
+		// ensuring a lower level keeps the full parse
+		require.NoError(t, node.EnsureParsed(PARSE_MODE_META))
+		assert.Equal(t, PARSE_MODE_FULL, node.GetParseMode())
+		assert.Len(t, node.GetTasks(), 4)
+	})
+
+	t.Run("Read meta before the first parse", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "node")
+		require.NoError(t, os.WriteFile(path, []byte("@meta\n  title: Alpha\n  id: alpha-id\n  type: project\n@end\n"), 0o644))
+
+		// each accessor on a fresh node, so none relies on another having parsed it
+		node, err := NewLocalNode(path)
+		require.NoError(t, err)
+		assert.Equal(t, "alpha-id", node.GetID())
+		assert.Equal(t, PARSE_MODE_META, node.GetParseMode())
+
+		node, err = NewLocalNode(path)
+		require.NoError(t, err)
+		assert.Equal(t, "Alpha", node.GetName())
+
+		node, err = NewLocalNode(path)
+		require.NoError(t, err)
+		assert.Equal(t, "project", node.GetMeta()["type"])
+	})
+
+	t.Run("Typed meta accessors", func(t *testing.T) {
+		path, err := filepath.Abs("../../test-data/wiki/schema/projects/alpha")
+		require.NoError(t, err)
+
+		node, err := NewLocalNode(path)
+		require.NoError(t, err)
+
+		value, ok := node.GetMetaValue("status")
+		assert.True(t, ok)
+		assert.Equal(t, "active", value)
+		_, ok = node.GetMetaValue("missing")
+		assert.False(t, ok)
+		assert.Equal(t, []string{"Jane", "people/jane"}, node.GetMetaList("members"))
+		due, ok := node.GetMetaDate("due")
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local), due)
+		_, ok = node.GetMetaDate("status")
+		assert.False(t, ok)
+	})
+
 	t.Run("Get tasks", func(t *testing.T) {
 		path, err := filepath.Abs("../../test-data/wiki/tasks/sample")
 		require.NoError(t, err)
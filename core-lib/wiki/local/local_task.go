@@ -0,0 +1,42 @@
+package local
+
+import (
+	"time"
+
+	"github.com/3rd/core/core-lib/fs"
+	"github.com/3rd/core/core-lib/wiki"
+)
+
+// editText applies a Syslang edit to the node file
+func (n *LocalNode) editText(edit func(text string) (string, error)) error {
+	text, err := n.Text()
+	if err != nil {
+		return err
+	}
+	text, err = edit(text)
+	if err != nil {
+		return err
+	}
+	return fs.WriteFileAtomic(n.GetPath(), []byte(text), 0o644)
+}
+
+// StartTaskSession appends an open session after the task's last session or schedule
+func (n *LocalNode) StartTaskSession(task *wiki.Task, now time.Time) error {
+	return n.editText(func(text string) (string, error) {
+		return wiki.StartTaskSessionText(text, task, now), nil
+	})
+}
+
+// StopTaskSession closes the task's last session, dropping the previous one if it started in the same minute
+func (n *LocalNode) StopTaskSession(task *wiki.Task, now time.Time) error {
+	return n.editText(func(text string) (string, error) {
+		return wiki.StopTaskSessionText(text, task, now)
+	})
+}
+
+// ToggleTaskDone flips the done state of a task, or today's completion for recurring tasks
+func (n *LocalNode) ToggleTaskDone(task *wiki.Task, now time.Time) error {
+	return n.editText(func(text string) (string, error) {
+		return wiki.ToggleTaskDoneText(text, task, now), nil
+	})
+}
@@ -0,0 +1,68 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/3rd/core/core-lib/wiki"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalTask(t *testing.T) {
+	createNode := func(t *testing.T, content string) *LocalNode {
+		path := filepath.Join(t.TempDir(), "node")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		node, err := NewLocalNode(path)
+		require.NoError(t, err)
+		require.NoError(t, node.Parse(PARSE_MODE_FULL))
+		return node
+	}
+	now := time.Date(2024, 1, 2, 10, 30, 0, 0, time.Local)
+
+	t.Run("Start and stop a session", func(t *testing.T) {
+		node := createNode(t, "* Section\n  [-] task\n")
+
+		err := node.StartTaskSession(node.GetTasks()[0], now)
+		require.NoError(t, err)
+		content, err := node.GetContent()
+		require.NoError(t, err)
+		assert.Equal(t, "* Section\n  [-] task\n    Session: 2024.01.02 10:30\n", content)
+
+		require.NoError(t, node.Parse(PARSE_MODE_FULL))
+		err = node.StopTaskSession(node.GetTasks()[0], now.Add(time.Hour))
+		require.NoError(t, err)
+		content, err = node.GetContent()
+		require.NoError(t, err)
+		assert.Equal(t, "* Section\n  [-] task\n    Session: 2024.01.02 10:30-11:30\n", content)
+	})
+
+	t.Run("Stop without session", func(t *testing.T) {
+		node := createNode(t, "[-] task\n")
+		err := node.StopTaskSession(node.GetTasks()[0], now)
+		assert.ErrorIs(t, err, wiki.ErrNoTaskSession)
+	})
+
+	t.Run("Stop with a closed last session", func(t *testing.T) {
+		content := "[-] task\n  Session: 2024.01.02 09:00-10:00\n"
+		node := createNode(t, content)
+		err := node.StopTaskSession(node.GetTasks()[0], now)
+		assert.ErrorIs(t, err, wiki.ErrNoTaskSession)
+
+		text, err := node.GetContent()
+		require.NoError(t, err)
+		assert.Equal(t, content, text)
+	})
+
+	t.Run("Toggle done", func(t *testing.T) {
+		node := createNode(t, "[-] task\n  Session: 2024.01.02 09:00-10:00\n")
+
+		err := node.ToggleTaskDone(node.GetTasks()[0], now)
+		require.NoError(t, err)
+		content, err := node.GetContent()
+		require.NoError(t, err)
+		assert.Equal(t, "[x] task\n  Session: 2024.01.02 09:00-10:00\n", content)
+	})
+}
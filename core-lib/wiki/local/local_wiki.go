@@ -1,11 +1,18 @@
 package local
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/3rd/core/core-lib/fs"
 	"github.com/3rd/core/core-lib/wiki"
+	"github.com/3rd/core/core-lib/wiki/search"
+	"github.com/3rd/syslang/go-syslang/pkg/syslang"
 )
 
 type PARSE_MODE string
@@ -22,9 +29,14 @@ type LocalWikiConfig struct {
 	SkipInitialLoad bool
 }
 
+// LocalWiki is safe for concurrent use, reloads replace nodes, backlinks and index
+// under mutex instead of changing them in place
 type LocalWiki struct {
-	config LocalWikiConfig
-	nodes  []*LocalNode
+	config    LocalWikiConfig
+	mutex     sync.RWMutex
+	nodes     []*LocalNode
+	backlinks map[string][]*LocalNode
+	index     *search.Index
 }
 
 func NewLocalWiki(config LocalWikiConfig) (*LocalWiki, error) {
@@ -40,13 +52,27 @@ func NewLocalWiki(config LocalWikiConfig) (*LocalWiki, error) {
 	return &wiki, nil
 }
 
-func (w *LocalWiki) GetNodes() ([]*LocalNode, error) {
-	return w.nodes, nil
+var _ wiki.Wiki = (*LocalWiki)(nil)
+
+// getNodes returns the current nodes, the slice is replaced on reloads and never changed in place
+func (w *LocalWiki) getNodes() []*LocalNode {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.nodes
 }
 
-func (w *LocalWiki) FindNodes(filter wiki.NodeFilter) ([]*LocalNode, error) {
-	var nodes []*LocalNode
-	for _, node := range w.nodes {
+// GetNodes returns the nodes as wiki.Node, see GetLocalNodes for the local node API
+func (w *LocalWiki) GetNodes() ([]wiki.Node, error) {
+	return toNodes(w.getNodes()), nil
+}
+
+func (w *LocalWiki) GetLocalNodes() ([]*LocalNode, error) {
+	return w.getNodes(), nil
+}
+
+func (w *LocalWiki) FindNodes(filter wiki.NodeFilter) ([]wiki.Node, error) {
+	nodes := []wiki.Node{}
+	for _, node := range w.getNodes() {
 		if filter(node) {
 			nodes = append(nodes, node)
 		}
@@ -54,8 +80,12 @@ func (w *LocalWiki) FindNodes(filter wiki.NodeFilter) ([]*LocalNode, error) {
 	return nodes, nil
 }
 
-func (w *LocalWiki) GetNode(id string) (*LocalNode, error) {
-	for _, node := range w.nodes {
+func (w *LocalWiki) GetNode(id string) (wiki.Node, error) {
+	return toNode(w.GetLocalNode(id))
+}
+
+func (w *LocalWiki) GetLocalNode(id string) (*LocalNode, error) {
+	for _, node := range w.getNodes() {
 		if node.GetID() == id {
 			return node, nil
 		}
@@ -63,8 +93,21 @@ func (w *LocalWiki) GetNode(id string) (*LocalNode, error) {
 	return nil, nil
 }
 
-func (w *LocalWiki) FindNode(filter wiki.NodeFilter) (*LocalNode, error) {
-	for _, node := range w.nodes {
+func (w *LocalWiki) GetNodeByName(name string) (wiki.Node, error) {
+	return toNode(w.getLocalNodeByName(name), nil)
+}
+
+func (w *LocalWiki) getLocalNodeByName(name string) *LocalNode {
+	for _, node := range w.getNodes() {
+		if node.GetName() == name {
+			return node
+		}
+	}
+	return nil
+}
+
+func (w *LocalWiki) FindNode(filter wiki.NodeFilter) (wiki.Node, error) {
+	for _, node := range w.getNodes() {
 		if filter(node) {
 			return node, nil
 		}
@@ -72,6 +115,43 @@ func (w *LocalWiki) FindNode(filter wiki.NodeFilter) (*LocalNode, error) {
 	return nil, nil
 }
 
+// toNode keeps missing nodes nil as wiki.Node
+func toNode(node *LocalNode, err error) (wiki.Node, error) {
+	if node == nil || err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func toNodes(localNodes []*LocalNode) []wiki.Node {
+	nodes := make([]wiki.Node, 0, len(localNodes))
+	for _, node := range localNodes {
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// loadNode reads a node of the wiki at the configured parse mode
+func (w *LocalWiki) loadNode(path string) (*LocalNode, error) {
+	node, err := NewLocalNode(path)
+	if err != nil {
+		return nil, err
+	}
+	node.root = w.config.Root
+	if w.config.Parse != PARSE_MODE_NONE {
+		if err := node.Parse(w.config.Parse); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+func sortNodes(nodes []*LocalNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].GetName() < nodes[j].GetName()
+	})
+}
+
 func (w *LocalWiki) Reload() error {
 	// walk root
 	files, err := fs.WalkFiles(w.config.Root, nil)
@@ -87,27 +167,258 @@ func (w *LocalWiki) Reload() error {
 	for _, file := range files {
 		go func(file fs.File) {
 			defer wg.Done()
-			node, err := NewLocalNode(file.GetPath())
+			node, err := w.loadNode(file.GetPath())
 			if err != nil {
 				return
 			}
-			if w.config.Parse != PARSE_MODE_NONE {
-				err = node.Parse(w.config.Parse)
-				if err != nil {
-					return
-				}
-			}
 			mutex.Lock()
 			nodes = append(nodes, node)
 			mutex.Unlock()
 		}(file)
 	}
 	wg.Wait()
+	sortNodes(nodes)
 
-	sort.SliceStable(nodes, func(i, j int) bool {
-		return nodes[i].GetName() < nodes[j].GetName()
-	})
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.nodes = nodes
+	w.backlinks = nil
+	w.index = nil
+	return nil
+}
 
+// ReloadPath updates the node of a single changed, created or removed file, keeping the search index
+// up to date, changes to directories reload everything
+func (w *LocalWiki) ReloadPath(path string) error {
+	stat, err := os.Stat(path)
+	if err == nil && stat.IsDir() {
+		return w.Reload()
+	}
+	// removed directory
+	for _, node := range w.getNodes() {
+		if strings.HasPrefix(node.GetPath(), path+string(os.PathSeparator)) {
+			return w.Reload()
+		}
+	}
+
+	// parse outside the lock, readers keep the previous node meanwhile
+	var node *LocalNode
+	isNode := err == nil && !fs.IsIgnored(path) && strings.HasPrefix(path, w.config.Root)
+	if isNode {
+		node, err = w.loadNode(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	nodes := []*LocalNode{}
+	for _, existing := range w.nodes {
+		if existing.GetPath() != path {
+			nodes = append(nodes, existing)
+		}
+	}
+	if w.index != nil {
+		w.index.Remove(path)
+	}
+	if node != nil {
+		nodes = append(nodes, node)
+		if w.index != nil {
+			if err := indexNode(w.index, node); err != nil {
+				return err
+			}
+		}
+	}
+	sortNodes(nodes)
 	w.nodes = nodes
+	w.backlinks = nil
 	return nil
 }
+
+// CreateNode writes a new node file and loads it, refusing to reuse an existing node name or file,
+// the name comes from the content title or else the file name and has to match name
+func (w *LocalWiki) CreateNode(name string, path string, content string) (wiki.Node, error) {
+	if existing := w.getLocalNodeByName(name); existing != nil {
+		return nil, fmt.Errorf("%w: %s (%s)", wiki.ErrNodeExists, name, existing.GetPath())
+	}
+	if relativePath, err := filepath.Rel(w.config.Root, path); err == nil {
+		existing, err := w.GetLocalNode(filepath.ToSlash(relativePath))
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, fmt.Errorf("%w: %s (%s)", wiki.ErrNodeExists, existing.GetID(), existing.GetPath())
+		}
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%w: %s", wiki.ErrNodeExists, path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	document, err := syslang.NewDocument(content)
+	if err != nil {
+		return nil, err
+	}
+	contentName := document.GetTitle()
+	if contentName == "" {
+		contentName = filepath.Base(path)
+	}
+	if contentName != name {
+		return nil, fmt.Errorf("%w: %q is named %q", wiki.ErrNodeNameMismatch, name, contentName)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := fs.WriteFileAtomic(path, []byte(content), 0o644); err != nil {
+		return nil, err
+	}
+	if err := w.ReloadPath(path); err != nil {
+		return nil, err
+	}
+	for _, node := range w.getNodes() {
+		if node.GetPath() == path {
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to load created node: %s", path)
+}
+
+// UpdateNode replaces the content of a node file
+func (w *LocalWiki) UpdateNode(id string, content string) error {
+	node, err := w.GetLocalNode(id)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return fmt.Errorf("node not found: %s", id)
+	}
+	if err := fs.WriteFileAtomic(node.GetPath(), []byte(content), 0o644); err != nil {
+		return err
+	}
+	return w.ReloadPath(node.GetPath())
+}
+
+// editTask runs a task edit on the node file of the task and reloads it
+func (w *LocalWiki) editTask(task *wiki.Task, edit func(node *LocalNode) error) error {
+	node, ok := task.Node.(*LocalNode)
+	if !ok || node == nil {
+		return fmt.Errorf("task %q doesn't belong to a local node", task.Text)
+	}
+	if err := edit(node); err != nil {
+		return err
+	}
+	return w.ReloadPath(node.GetPath())
+}
+
+func (w *LocalWiki) StartTaskSession(task *wiki.Task, now time.Time) error {
+	return w.editTask(task, func(node *LocalNode) error {
+		return node.StartTaskSession(task, now)
+	})
+}
+
+func (w *LocalWiki) StopTaskSession(task *wiki.Task, now time.Time) error {
+	return w.editTask(task, func(node *LocalNode) error {
+		return node.StopTaskSession(task, now)
+	})
+}
+
+func (w *LocalWiki) ToggleTaskDone(task *wiki.Task, now time.Time) error {
+	return w.editTask(task, func(node *LocalNode) error {
+		return node.ToggleTaskDone(task, now)
+	})
+}
+
+func indexNode(index *search.Index, node *LocalNode) error {
+	text, err := node.Text()
+	if err != nil {
+		return err
+	}
+	index.Add(node.GetPath(), node.GetID(), text)
+	return nil
+}
+
+// GetIndex returns the full-text index, built on first use and kept up to date by ReloadPath
+func (w *LocalWiki) GetIndex() (*search.Index, error) {
+	w.mutex.RLock()
+	index := w.index
+	w.mutex.RUnlock()
+	if index != nil {
+		return index, nil
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.index != nil {
+		return w.index, nil
+	}
+	index = search.NewIndex()
+	for _, node := range w.nodes {
+		if err := indexNode(index, node); err != nil {
+			return nil, err
+		}
+	}
+	w.index = index
+	return index, nil
+}
+
+// Search runs a full-text query, see search.Index.Search
+func (w *LocalWiki) Search(query string, limit int) ([]search.Result, error) {
+	index, err := w.GetIndex()
+	if err != nil {
+		return nil, err
+	}
+	return index.Search(query, limit), nil
+}
+
+// getBacklinks returns the nodes and their backlinks, building the backlinks on first use
+func (w *LocalWiki) getBacklinks() ([]*LocalNode, map[string][]*LocalNode) {
+	w.mutex.RLock()
+	nodes, backlinks := w.nodes, w.backlinks
+	w.mutex.RUnlock()
+	if backlinks != nil {
+		return nodes, backlinks
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.backlinks == nil {
+		w.backlinks = wiki.BuildBacklinks(w.nodes)
+	}
+	return w.nodes, w.backlinks
+}
+
+// GetLinks returns the outgoing links of a node, a missing node has no links
+func (w *LocalWiki) GetLinks(id string) ([]wiki.Link, error) {
+	node, err := w.GetLocalNode(id)
+	if err != nil || node == nil {
+		return nil, err
+	}
+	return node.GetLinks(), nil
+}
+
+// GetBacklinks returns the nodes linking to a node
+func (w *LocalWiki) GetBacklinks(id string) ([]wiki.Node, error) {
+	_, backlinks := w.getBacklinks()
+	return toNodes(backlinks[id]), nil
+}
+
+// GetOrphans returns the nodes no other node links to
+func (w *LocalWiki) GetOrphans() ([]wiki.Node, error) {
+	nodes, backlinks := w.getBacklinks()
+	orphans := []wiki.Node{}
+	for _, node := range nodes {
+		if len(backlinks[node.GetID()]) == 0 {
+			orphans = append(orphans, node)
+		}
+	}
+	return orphans, nil
+}
+
+// GetGraph returns the nodes and the resolved links between them
+func (w *LocalWiki) GetGraph() (*wiki.Graph, error) {
+	nodes, backlinks := w.getBacklinks()
+	return wiki.NewGraph(nodes, backlinks), nil
+}
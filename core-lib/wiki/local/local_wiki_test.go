@@ -1,8 +1,10 @@
 package local
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/3rd/core/core-lib/wiki"
@@ -94,4 +96,256 @@ func TestLocalWiki(t *testing.T) {
 		err = os.Remove(tmpPath)
 		require.NoError(t, err)
 	})
+
+	t.Run("Links and backlinks", func(t *testing.T) {
+		linksPath, err := filepath.Abs("../../test-data/wiki/export")
+		require.NoError(t, err)
+		localWiki, err := NewLocalWiki(LocalWikiConfig{Root: linksPath, Parse: PARSE_MODE_META})
+		require.NoError(t, err)
+
+		links, err := localWiki.GetLinks("notes/note-1")
+		require.NoError(t, err)
+		require.Len(t, links, 1)
+		assert.Equal(t, "Project A", links[0].Target)
+
+		backlinks, err := localWiki.GetBacklinks("project-a")
+		require.NoError(t, err)
+		require.Len(t, backlinks, 1)
+		assert.Equal(t, "notes/note-1", backlinks[0].GetID())
+
+		orphans, err := localWiki.GetOrphans()
+		require.NoError(t, err)
+		require.Len(t, orphans, 1)
+		assert.Equal(t, "notes/note-1", orphans[0].GetID())
+
+		graph, err := localWiki.GetGraph()
+		require.NoError(t, err)
+		assert.Len(t, graph.Nodes, 2)
+		assert.Equal(t, []wiki.GraphEdge{{Source: "notes/note-1", Target: "project-a"}}, graph.Edges)
+
+		links, err = localWiki.GetLinks("missing")
+		assert.NoError(t, err)
+		assert.Nil(t, links)
+	})
+
+	t.Run("ReloadPath updates nodes and the search index", func(t *testing.T) {
+		root := t.TempDir()
+		path := filepath.Join(root, "node")
+		require.NoError(t, os.WriteFile(path, []byte("* Kitchen\n"), 0o644))
+		localWiki, err := NewLocalWiki(LocalWikiConfig{Root: root, Parse: PARSE_MODE_META})
+		require.NoError(t, err)
+
+		results, err := localWiki.Search("kitchen", 0)
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+
+		require.NoError(t, os.WriteFile(path, []byte("* Garden\n"), 0o644))
+		require.NoError(t, localWiki.ReloadPath(path))
+		results, err = localWiki.Search("kitchen", 0)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+
+		created := filepath.Join(root, "created")
+		require.NoError(t, os.WriteFile(created, []byte("* Kitchen\n"), 0o644))
+		require.NoError(t, localWiki.ReloadPath(created))
+		nodes, err := localWiki.GetNodes()
+		require.NoError(t, err)
+		assert.Len(t, nodes, 2)
+
+		require.NoError(t, os.Remove(created))
+		require.NoError(t, localWiki.ReloadPath(created))
+		nodes, err = localWiki.GetNodes()
+		require.NoError(t, err)
+		assert.Len(t, nodes, 1)
+		results, err = localWiki.Search("kitchen", 0)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("CreateNode refuses collisions", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "existing"), []byte("@meta\n  title: Alpha\n@end\n"), 0o644))
+		localWiki, err := NewLocalWiki(LocalWikiConfig{Root: root, Parse: PARSE_MODE_META})
+		require.NoError(t, err)
+
+		path := filepath.Join(root, "projects", "beta")
+		node, err := localWiki.CreateNode("Beta", path, "@meta\n  title: Beta\n@end\n")
+		require.NoError(t, err)
+		assert.Equal(t, "projects/beta", node.GetID())
+		assert.Equal(t, "Beta", node.GetName())
+		assert.FileExists(t, path)
+
+		_, err = localWiki.CreateNode("Alpha", filepath.Join(root, "alpha"), "")
+		assert.ErrorIs(t, err, wiki.ErrNodeExists)
+		_, err = localWiki.CreateNode("Gamma", filepath.Join(root, "existing"), "")
+		assert.ErrorIs(t, err, wiki.ErrNodeExists)
+		_, err = localWiki.CreateNode("Gamma", path, "")
+		assert.ErrorIs(t, err, wiki.ErrNodeExists)
+		assert.NoFileExists(t, filepath.Join(root, "alpha"))
+
+		_, err = localWiki.CreateNode("Delta", filepath.Join(root, "delta"), "@meta\n  title: Epsilon\n@end\n")
+		assert.ErrorIs(t, err, wiki.ErrNodeNameMismatch)
+		_, err = localWiki.CreateNode("Delta", filepath.Join(root, "zeta"), "")
+		assert.ErrorIs(t, err, wiki.ErrNodeNameMismatch)
+		assert.NoFileExists(t, filepath.Join(root, "zeta"))
+
+		node, err = localWiki.CreateNode("delta", filepath.Join(root, "delta"), "")
+		require.NoError(t, err)
+		assert.Equal(t, "delta", node.GetName())
+	})
+
+	t.Run("RenameNode rewrites references", func(t *testing.T) {
+		root := t.TempDir()
+		alphaPath := filepath.Join(root, "alpha")
+		notePath := filepath.Join(root, "note")
+		require.NoError(t, os.WriteFile(alphaPath, []byte("@meta\n  title: Alpha\n@end\n\n* Alpha\n  [[Alpha]]\n"), 0o644))
+		require.NoError(t, os.WriteFile(notePath, []byte("* Note\n  [[Alpha]] [[Alpha|a]]\n"), 0o644))
+		localWiki, err := NewLocalWiki(LocalWikiConfig{Root: root, Parse: PARSE_MODE_META})
+		require.NoError(t, err)
+
+		betaPath := filepath.Join(root, "beta")
+		result, err := localWiki.RenameNode("alpha", RenameOptions{Title: "Beta", Path: betaPath, DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, "Beta", result.NewName)
+		assert.Equal(t, "beta", result.NewID)
+		assert.ElementsMatch(t, []RenameChange{{Path: betaPath, Links: 1}, {Path: notePath, Links: 2}}, result.Changes)
+		assert.FileExists(t, alphaPath)
+		assert.NoFileExists(t, betaPath)
+
+		_, err = localWiki.RenameNode("alpha", RenameOptions{Title: "note"})
+		assert.ErrorIs(t, err, wiki.ErrNodeExists)
+
+		_, err = localWiki.RenameNode("alpha", RenameOptions{Title: "Beta", Path: betaPath})
+		require.NoError(t, err)
+		assert.NoFileExists(t, alphaPath)
+		content, err := os.ReadFile(betaPath)
+		require.NoError(t, err)
+		assert.Equal(t, "@meta\n  title: Beta\n@end\n\n* Alpha\n  [[Beta]]\n", string(content))
+		content, err = os.ReadFile(notePath)
+		require.NoError(t, err)
+		assert.Equal(t, "* Note\n  [[Beta]] [[Beta|a]]\n", string(content))
+		node, err := localWiki.GetNode("beta")
+		require.NoError(t, err)
+		require.NotNil(t, node)
+		assert.Equal(t, "Beta", node.GetName())
+
+		// untitled nodes are renamed through their file
+		result, err = localWiki.RenameNode("note", RenameOptions{Path: filepath.Join(root, "notes", "renamed")})
+		require.NoError(t, err)
+		assert.Equal(t, "renamed", result.NewName)
+		assert.Equal(t, "notes/renamed", result.NewID)
+	})
+
+	t.Run("RenameNode stays inside the root", func(t *testing.T) {
+		parent := t.TempDir()
+		root := filepath.Join(parent, "wiki")
+		require.NoError(t, os.Mkdir(root, 0o755))
+		alphaPath := filepath.Join(root, "alpha")
+		require.NoError(t, os.WriteFile(alphaPath, []byte("* Alpha\n"), 0o644))
+		localWiki, err := NewLocalWiki(LocalWikiConfig{Root: root, Parse: PARSE_MODE_META})
+		require.NoError(t, err)
+
+		for _, path := range []string{filepath.Join(root, "..", "x"), filepath.Join(parent, "wiki-other", "x"), root, "/tmp/x"} {
+			_, err = localWiki.RenameNode("alpha", RenameOptions{Path: path})
+			assert.ErrorIs(t, err, ErrOutsideRoot, path)
+		}
+		assert.FileExists(t, alphaPath)
+		assert.NoFileExists(t, filepath.Join(parent, "x"))
+	})
+
+	t.Run("Stable IDs", func(t *testing.T) {
+		root := t.TempDir()
+		path := filepath.Join(root, "projects", "alpha")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("@meta\n  title: Alpha\n@end\n"), 0o644))
+		localWiki, err := NewLocalWiki(LocalWikiConfig{Root: root, Parse: PARSE_MODE_META})
+		require.NoError(t, err)
+
+		stamped, err := localWiki.StampIDs(true)
+		require.NoError(t, err)
+		require.Len(t, stamped, 1)
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "@meta\n  title: Alpha\n@end\n", string(content))
+
+		stamped, err = localWiki.StampIDs(false)
+		require.NoError(t, err)
+		require.Len(t, stamped, 1)
+		content, err = os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "@meta\n  title: Alpha\n  id: projects/alpha\n@end\n", string(content))
+
+		// titles and paths change, the id doesn't
+		result, err := localWiki.RenameNode("projects/alpha", RenameOptions{Title: "Beta", Path: filepath.Join(root, "archive", "beta")})
+		require.NoError(t, err)
+		assert.Equal(t, "projects/alpha", result.NewID)
+		node, err := localWiki.GetNode("projects/alpha")
+		require.NoError(t, err)
+		require.NotNil(t, node)
+		assert.Equal(t, "Beta", node.GetName())
+		assert.Equal(t, filepath.Join(root, "archive", "beta"), node.GetPath())
+
+		stamped, err = localWiki.StampIDs(false)
+		require.NoError(t, err)
+		assert.Empty(t, stamped)
+	})
+
+	// run with -race, the watcher reloads while readers build backlinks and search
+	t.Run("Concurrent ReloadPath and reads", func(t *testing.T) {
+		root := t.TempDir()
+		alphaPath := filepath.Join(root, "alpha")
+		require.NoError(t, os.WriteFile(alphaPath, []byte("* Alpha\n  [[beta]]\n"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "beta"), []byte("* Beta\n  [[alpha]]\n"), 0o644))
+		localWiki, err := NewLocalWiki(LocalWikiConfig{Root: root, Parse: PARSE_MODE_META})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				content := fmt.Sprintf("* Alpha %d\n  [[beta]]\n", i)
+				if err := os.WriteFile(alphaPath, []byte(content), 0o644); err != nil {
+					t.Error(err)
+					return
+				}
+				if err := localWiki.ReloadPath(alphaPath); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if _, err := localWiki.GetBacklinks("beta"); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := localWiki.GetOrphans(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if _, err := localWiki.Search("alpha", 10); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := localWiki.GetNodes(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+		wg.Wait()
+
+		backlinks, err := localWiki.GetBacklinks("beta")
+		require.NoError(t, err)
+		require.Len(t, backlinks, 1)
+		assert.Equal(t, "alpha", backlinks[0].GetID())
+	})
 }
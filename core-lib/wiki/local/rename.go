@@ -0,0 +1,189 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/3rd/core/core-lib/fs"
+	"github.com/3rd/core/core-lib/wiki"
+)
+
+var ErrOutsideRoot = errors.New("path is outside the wiki root")
+
+// RenameOptions describes a node rename, empty fields keep the current title or path
+type RenameOptions struct {
+	Title  string
+	Path   string
+	DryRun bool
+}
+
+// RenameChange is a node whose links were rewritten
+type RenameChange struct {
+	Path  string
+	Links int
+}
+
+type RenameResult struct {
+	OldID   string
+	NewID   string
+	OldName string
+	NewName string
+	OldPath string
+	NewPath string
+	Changes []RenameChange
+}
+
+// setMetaValue replaces or adds a key of the @meta block, adding the block if there's none
+func setMetaValue(text string, key string, value string) string {
+	lines := strings.Split(text, "\n")
+	start, end, ok := wiki.FindMetaBlock(lines)
+	if !ok {
+		return "@meta\n  " + key + ": " + value + "\n@end\n\n" + text
+	}
+	for i := start + 1; i < end; i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, key+":") {
+			indent := lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " \t"))]
+			lines[i] = indent + key + ": " + value
+			return strings.Join(lines, "\n")
+		}
+	}
+	updated := append([]string{}, lines[:end]...)
+	updated = append(updated, "  "+key+": "+value)
+	return strings.Join(append(updated, lines[end:]...), "\n")
+}
+
+// isInsideRoot reports whether path is a file below root, the root itself doesn't count
+func isInsideRoot(root string, path string) bool {
+	relativePath, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return relativePath != "." && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
+
+// RenameNode changes the title and/or the path of a node and rewrites the links of every node
+// referencing its name, in dry-run mode nothing is written
+func (w *LocalWiki) RenameNode(id string, options RenameOptions) (*RenameResult, error) {
+	node, err := w.GetLocalNode(id)
+	if err != nil {
+		return nil, err
+	}
+	if node == nil {
+		return nil, fmt.Errorf("node not found: %s", id)
+	}
+	if err := node.EnsureParsed(PARSE_MODE_META); err != nil {
+		return nil, err
+	}
+
+	result := RenameResult{
+		OldID:   id,
+		NewID:   id,
+		OldName: node.GetName(),
+		NewName: node.GetName(),
+		OldPath: node.GetPath(),
+		NewPath: node.GetPath(),
+	}
+	if options.Path != "" {
+		result.NewPath = filepath.Clean(options.Path)
+		if !isInsideRoot(w.config.Root, result.NewPath) {
+			return nil, fmt.Errorf("%w: %s", ErrOutsideRoot, result.NewPath)
+		}
+	}
+	if options.Title != "" {
+		result.NewName = options.Title
+	} else if node.GetMeta()["title"] == "" {
+		result.NewName = filepath.Base(result.NewPath)
+	}
+	if node.GetMeta()[wiki.ID_META_KEY] == "" {
+		relativePath, err := filepath.Rel(w.config.Root, result.NewPath)
+		if err != nil {
+			return nil, err
+		}
+		result.NewID = filepath.ToSlash(relativePath)
+	}
+
+	if result.NewName != result.OldName {
+		existing, err := w.GetNodeByName(result.NewName)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, fmt.Errorf("%w: %s (%s)", wiki.ErrNodeExists, result.NewName, existing.GetPath())
+		}
+	}
+	if result.NewPath != result.OldPath {
+		if _, err := os.Stat(result.NewPath); err == nil {
+			return nil, fmt.Errorf("%w: %s", wiki.ErrNodeExists, result.NewPath)
+		}
+	}
+
+	text, err := node.Text()
+	if err != nil {
+		return nil, err
+	}
+	if options.Title != "" {
+		text = setMetaValue(text, "title", options.Title)
+	}
+
+	// collect rewritten references, self references live in the renamed node
+	updates := map[string]string{}
+	if result.NewName != result.OldName {
+		var count int
+		text, count = wiki.RewriteLinks(text, result.OldName, result.NewName)
+		if count > 0 {
+			result.Changes = append(result.Changes, RenameChange{Path: result.NewPath, Links: count})
+		}
+		backlinks, err := w.GetBacklinks(result.OldID)
+		if err != nil {
+			return nil, err
+		}
+		for _, backlink := range backlinks {
+			backlinkText, err := backlink.GetContent()
+			if err != nil {
+				return nil, err
+			}
+			backlinkText, count = wiki.RewriteLinks(backlinkText, result.OldName, result.NewName)
+			if count == 0 {
+				continue
+			}
+			updates[backlink.GetPath()] = backlinkText
+			result.Changes = append(result.Changes, RenameChange{Path: backlink.GetPath(), Links: count})
+		}
+	}
+	if options.DryRun {
+		return &result, nil
+	}
+
+	// the old file is removed last so a failed write never loses the node,
+	// files written before a failure are reported since they can't be rolled back atomically
+	if err := os.MkdirAll(filepath.Dir(result.NewPath), 0o755); err != nil {
+		return nil, err
+	}
+	if err := fs.WriteFileAtomic(result.NewPath, []byte(text), 0o644); err != nil {
+		return nil, err
+	}
+	written := []string{result.NewPath}
+	updatePaths := make([]string, 0, len(updates))
+	for path := range updates {
+		updatePaths = append(updatePaths, path)
+	}
+	sort.Strings(updatePaths)
+	for _, path := range updatePaths {
+		if err := fs.WriteFileAtomic(path, []byte(updates[path]), 0o644); err != nil {
+			return nil, fmt.Errorf("rename of %s interrupted, already written: %s: %w", result.OldPath, strings.Join(written, ", "), err)
+		}
+		written = append(written, path)
+	}
+	if result.NewPath != result.OldPath {
+		if err := os.Remove(result.OldPath); err != nil {
+			return nil, fmt.Errorf("rename of %s interrupted, already written: %s: %w", result.OldPath, strings.Join(written, ", "), err)
+		}
+	}
+
+	return &result, w.Reload()
+}
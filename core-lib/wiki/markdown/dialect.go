@@ -0,0 +1,173 @@
+package markdown
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// task checkbox styles
+const (
+	CHECKBOX_STYLE_SYSLANG  = "syslang"
+	CHECKBOX_STYLE_OBSIDIAN = "obsidian"
+)
+
+// obsidianCheckboxes maps Syslang task markers to the states understood by Obsidian task plugins
+var obsidianCheckboxes = map[string]string{
+	" ": " ",
+	"-": "/",
+	"x": "x",
+	"_": "-",
+}
+
+var (
+	dialectLinkRe     = regexp.MustCompile(`\[\[([^\[\]|]+)(?:\|([^\[\]]+))?\]\]`)
+	dialectCheckboxRe = regexp.MustCompile(`^(\s*- \[)(.)(\] )`)
+	dialectFenceRe    = regexp.MustCompile("^\\s*```")
+	yamlPlainRe       = regexp.MustCompile(`^[A-Za-z0-9_./][A-Za-z0-9 _./,()-]*$`)
+)
+
+// LinkResolver maps link targets between node names and paths relative to the mount root.
+type LinkResolver interface {
+	// ResolveLink returns the path (without extension) of the node with the given name
+	ResolveLink(name string) (string, bool)
+	// ResolveLinkName returns the name of the node at the given path
+	ResolveLinkName(path string) (string, bool)
+}
+
+// Dialect adjusts the Markdown served by the mount for a specific editor.
+type Dialect struct {
+	// Frontmatter prepends the node meta as YAML frontmatter
+	Frontmatter bool
+	// Links rewrites [[name]] links to [[path|name]] so they resolve inside the mount
+	Links LinkResolver
+	// CheckboxStyle selects how task markers are written
+	CheckboxStyle string
+}
+
+// IsZero reports whether the dialect leaves the Markdown untouched.
+func (d Dialect) IsZero() bool {
+	return !d.Frontmatter && d.Links == nil && (d.CheckboxStyle == "" || d.CheckboxStyle == CHECKBOX_STYLE_SYSLANG)
+}
+
+func (d Dialect) checkboxes(reverse bool) map[string]string {
+	if d.CheckboxStyle != CHECKBOX_STYLE_OBSIDIAN {
+		return nil
+	}
+	if !reverse {
+		return obsidianCheckboxes
+	}
+	result := map[string]string{}
+	for from, to := range obsidianCheckboxes {
+		result[to] = from
+	}
+	return result
+}
+
+func formatFrontmatterValue(value string) string {
+	if yamlPlainRe.MatchString(value) && strings.TrimSpace(value) == value {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+// FormatFrontmatter renders meta as a YAML frontmatter block with sorted keys.
+func FormatFrontmatter(meta map[string]string) string {
+	keys := []string{}
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	builder := strings.Builder{}
+	builder.WriteString("---\n")
+	for _, key := range keys {
+		builder.WriteString(key + ": " + formatFrontmatterValue(meta[key]) + "\n")
+	}
+	builder.WriteString("---\n")
+	return builder.String()
+}
+
+// mapLines applies fn to every line outside fenced code blocks
+func mapLines(text string, fn func(line string) string) string {
+	lines := strings.Split(text, "\n")
+	inCode := false
+	for i, line := range lines {
+		if dialectFenceRe.MatchString(line) {
+			inCode = !inCode
+			continue
+		}
+		if !inCode {
+			lines[i] = fn(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func mapCheckbox(line string, checkboxes map[string]string) string {
+	return dialectCheckboxRe.ReplaceAllStringFunc(line, func(match string) string {
+		parts := dialectCheckboxRe.FindStringSubmatch(match)
+		if to, ok := checkboxes[parts[2]]; ok {
+			return parts[1] + to + parts[3]
+		}
+		return match
+	})
+}
+
+// Apply converts Markdown from the Syslang dialect to this one.
+func (d Dialect) Apply(text string, meta map[string]string) string {
+	checkboxes := d.checkboxes(false)
+
+	text = mapLines(text, func(line string) string {
+		if checkboxes != nil {
+			line = mapCheckbox(line, checkboxes)
+		}
+		if d.Links != nil {
+			line = dialectLinkRe.ReplaceAllStringFunc(line, func(match string) string {
+				parts := dialectLinkRe.FindStringSubmatch(match)
+				path, ok := d.Links.ResolveLink(parts[1])
+				if !ok {
+					return match
+				}
+				label := parts[2]
+				if label == "" {
+					label = parts[1]
+				}
+				return "[[" + path + "|" + label + "]]"
+			})
+		}
+		return line
+	})
+
+	if d.Frontmatter && len(meta) > 0 {
+		text = FormatFrontmatter(meta) + "\n" + text
+	}
+	return text
+}
+
+// Revert converts Markdown written in this dialect back to the Syslang dialect.
+// Frontmatter is left in place, ToSyslang turns it into the meta block.
+func (d Dialect) Revert(text string) string {
+	checkboxes := d.checkboxes(true)
+
+	return mapLines(text, func(line string) string {
+		if checkboxes != nil {
+			line = mapCheckbox(line, checkboxes)
+		}
+		if d.Links != nil {
+			line = dialectLinkRe.ReplaceAllStringFunc(line, func(match string) string {
+				parts := dialectLinkRe.FindStringSubmatch(match)
+				name, ok := d.Links.ResolveLinkName(parts[1])
+				if !ok {
+					return match
+				}
+				if parts[2] == "" || parts[2] == name {
+					return "[[" + name + "]]"
+				}
+				return "[[" + name + "|" + parts[2] + "]]"
+			})
+		}
+		return line
+	})
+}
@@ -0,0 +1,54 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLinkResolver map[string]string
+
+func (r testLinkResolver) ResolveLink(name string) (string, bool) {
+	path, ok := r[name]
+	return path, ok
+}
+
+func (r testLinkResolver) ResolveLinkName(path string) (string, bool) {
+	for name, p := range r {
+		if p == path {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func TestDialect(t *testing.T) {
+	links := testLinkResolver{"Project A": "projects/a", "Project B": "projects/b"}
+	meta := map[string]string{"title": "Project A", "type": "project", "status": "wip: blocked"}
+	obsidian := Dialect{Frontmatter: true, Links: links, CheckboxStyle: CHECKBOX_STYLE_OBSIDIAN}
+
+	t.Run("Apply obsidian", func(t *testing.T) {
+		input := readGolden(t, "dialect.md")
+		expected := readGolden(t, "dialect.obsidian.md")
+		assert.Equal(t, expected, obsidian.Apply(input, meta))
+	})
+
+	t.Run("Revert obsidian", func(t *testing.T) {
+		input := readGolden(t, "dialect.md")
+		expected := readGolden(t, "dialect.obsidian.md")
+		body := strings.SplitN(expected, "---\n\n", 2)[1]
+		assert.Equal(t, input, obsidian.Revert(body))
+	})
+
+	t.Run("Zero dialect keeps markdown", func(t *testing.T) {
+		input := readGolden(t, "dialect.md")
+		assert.True(t, Dialect{}.IsZero())
+		assert.Equal(t, input, Dialect{}.Apply(input, meta))
+		assert.Equal(t, input, Dialect{}.Revert(input))
+	})
+
+	t.Run("Skip frontmatter without meta", func(t *testing.T) {
+		assert.Equal(t, "text\n", Dialect{Frontmatter: true}.Apply("text\n", map[string]string{}))
+	})
+}
@@ -0,0 +1,243 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlListItemRe = regexp.MustCompile(`^(\s*)([-*+]|\d+\.) (.*)$`)
+	htmlTaskRe     = regexp.MustCompile(`^\[([ x\-_/])\] (.*)$`)
+	htmlLinkRe     = regexp.MustCompile(`\[([^\[\]]+)\]\(([^()\s]+)\)`)
+	htmlStrongRe   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	htmlEmRe       = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+)
+
+// htmlTaskClasses maps task markers (both checkbox styles) to CSS classes
+var htmlTaskClasses = map[string]string{
+	" ": "task",
+	"-": "task task-active",
+	"/": "task task-active",
+	"x": "task task-done",
+	"_": "task task-cancelled",
+}
+
+// HTMLOptions configures ToHTML.
+type HTMLOptions struct {
+	// LinkHref returns the href for a [[name]] link, unresolved links are rendered as text
+	LinkHref func(name string) (string, bool)
+}
+
+type htmlRenderer struct {
+	options   HTMLOptions
+	builder   strings.Builder
+	paragraph []string
+	lists     []int
+}
+
+func (r *htmlRenderer) inline(text string) string {
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		// odd parts are code spans, unless the last backtick is unbalanced
+		if i%2 == 1 && i < len(parts)-1 {
+			parts[i] = "<code>" + html.EscapeString(part) + "</code>"
+			continue
+		}
+		if i%2 == 1 {
+			part = "`" + part
+		}
+		parts[i] = r.inlineText(part)
+	}
+	return strings.Join(parts, "")
+}
+
+func (r *htmlRenderer) inlineText(text string) string {
+	text = html.EscapeString(text)
+	text = dialectLinkRe.ReplaceAllStringFunc(text, func(match string) string {
+		parts := dialectLinkRe.FindStringSubmatch(match)
+		target := html.UnescapeString(parts[1])
+		label := parts[2]
+		if label == "" {
+			label = parts[1]
+		}
+		if r.options.LinkHref == nil {
+			return label
+		}
+		href, ok := r.options.LinkHref(target)
+		if !ok {
+			return `<span class="link-missing">` + label + `</span>`
+		}
+		return `<a href="` + html.EscapeString(href) + `">` + label + `</a>`
+	})
+	text = htmlLinkRe.ReplaceAllStringFunc(text, func(match string) string {
+		parts := htmlLinkRe.FindStringSubmatch(match)
+		if !isSafeHref(html.UnescapeString(parts[2])) {
+			return parts[1]
+		}
+		return `<a href="` + parts[2] + `">` + parts[1] + `</a>`
+	})
+	text = htmlStrongRe.ReplaceAllString(text, "<strong>$1</strong>")
+	text = htmlEmRe.ReplaceAllString(text, "<em>$1</em>")
+	return text
+}
+
+// isSafeHref allows http, https and mailto links and relative targets, other schemes such as javascript: are rendered as text
+func isSafeHref(href string) bool {
+	target, err := url.Parse(href)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(target.Scheme) {
+	case "":
+		// a relative target must not smuggle a scheme past the parser
+		return !strings.Contains(strings.SplitN(href, "/", 2)[0], ":")
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+func (r *htmlRenderer) flushParagraph() {
+	if len(r.paragraph) == 0 {
+		return
+	}
+	r.builder.WriteString("<p>" + strings.Join(r.paragraph, "\n") + "</p>\n")
+	r.paragraph = nil
+}
+
+func (r *htmlRenderer) closeLists(indent int) {
+	for len(r.lists) > 0 && r.lists[len(r.lists)-1] > indent {
+		r.builder.WriteString("</li>\n</ul>\n")
+		r.lists = r.lists[:len(r.lists)-1]
+	}
+}
+
+func (r *htmlRenderer) listItem(indent int, text string) {
+	r.flushParagraph()
+	r.closeLists(indent)
+	if len(r.lists) == 0 || indent > r.lists[len(r.lists)-1] {
+		if len(r.lists) > 0 {
+			r.builder.WriteString("\n")
+		}
+		r.builder.WriteString("<ul>\n")
+		r.lists = append(r.lists, indent)
+	} else {
+		r.builder.WriteString("</li>\n")
+	}
+
+	if match := htmlTaskRe.FindStringSubmatch(text); match != nil {
+		checked := ""
+		if match[1] == "x" {
+			checked = " checked"
+		}
+		fmt.Fprintf(&r.builder, `<li class="%s"><input type="checkbox" disabled%s> %s`, htmlTaskClasses[match[1]], checked, r.inline(match[2]))
+		return
+	}
+	r.builder.WriteString("<li>" + r.inline(text))
+}
+
+func (r *htmlRenderer) frontmatter(lines []string) {
+	entries := []string{}
+	for _, line := range lines {
+		// comments hold FromSyslang directives
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		entries = append(entries, fmt.Sprintf("<dt>%s</dt><dd>%s</dd>\n", html.EscapeString(strings.TrimSpace(key)), html.EscapeString(strings.Trim(strings.TrimSpace(value), `"`))))
+	}
+	if len(entries) == 0 {
+		return
+	}
+	r.builder.WriteString("<dl class=\"meta\">\n" + strings.Join(entries, "") + "</dl>\n")
+}
+
+// ToHTML renders the Markdown served by the mount as an HTML fragment.
+// It covers the constructs produced by FromSyslang and ToMarkdown: frontmatter, headings, nested lists, tasks,
+// fenced code, paragraphs and inline code, emphasis and links.
+func ToHTML(text string, options HTMLOptions) string {
+	r := htmlRenderer{options: options}
+	lines, _ := splitLines(text)
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		// frontmatter
+		if i == 0 && trimmed == "---" {
+			end := -1
+			for j := 1; j < len(lines); j++ {
+				if strings.TrimSpace(lines[j]) == "---" {
+					end = j
+					break
+				}
+			}
+			if end > 0 {
+				r.frontmatter(lines[1:end])
+				i = end
+				continue
+			}
+		}
+
+		// fenced code
+		if match := markdownFenceRe.FindStringSubmatch(line); match != nil {
+			r.flushParagraph()
+			r.closeLists(-1)
+			class := ""
+			if lang := strings.TrimSpace(match[2]); lang != "" {
+				class = ` class="language-` + html.EscapeString(lang) + `"`
+			}
+			code := []string{}
+			for i++; i < len(lines) && !markdownFenceRe.MatchString(lines[i]); i++ {
+				code = append(code, html.EscapeString(strings.TrimPrefix(lines[i], match[1])))
+			}
+			r.builder.WriteString("<pre><code" + class + ">" + strings.Join(code, "\n") + "</code></pre>\n")
+			continue
+		}
+
+		if trimmed == "" {
+			r.flushParagraph()
+			continue
+		}
+
+		// headings
+		if match := markdownHeadingRe.FindStringSubmatch(line); match != nil && len(match[1]) <= 6 {
+			r.flushParagraph()
+			r.closeLists(-1)
+			level := len(match[1])
+			fmt.Fprintf(&r.builder, "<h%d>%s</h%d>\n", level, r.inline(match[2]), level)
+			continue
+		}
+
+		// lists
+		if match := htmlListItemRe.FindStringSubmatch(line); match != nil {
+			r.listItem(len(match[1]), match[3])
+			continue
+		}
+
+		// continuation lines of list items (task properties)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if len(r.lists) > 0 && indent > r.lists[len(r.lists)-1] {
+			r.builder.WriteString("<br>" + r.inline(unescapeLine(trimmed)))
+			continue
+		}
+
+		r.closeLists(-1)
+		r.paragraph = append(r.paragraph, r.inline(unescapeLine(trimmed)))
+	}
+
+	r.flushParagraph()
+	r.closeLists(-1)
+	return r.builder.String()
+}
+
+// HTMLPage wraps a fragment rendered by ToHTML in a standalone document.
+func HTMLPage(title string, body string) string {
+	return "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>" + html.EscapeString(title) + "</title>\n</head>\n<body>\n" + body + "</body>\n</html>\n"
+}
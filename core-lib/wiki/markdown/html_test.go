@@ -0,0 +1,53 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTML(t *testing.T) {
+	linkHref := func(name string) (string, bool) {
+		if name == "Project B" {
+			return "project-b.html", true
+		}
+		return "", false
+	}
+
+	t.Run("Render golden", func(t *testing.T) {
+		input := readGolden(t, "html.md")
+		expected := readGolden(t, "html.html")
+		assert.Equal(t, expected, ToHTML(input, HTMLOptions{LinkHref: linkHref}))
+	})
+
+	t.Run("Links without resolver render as text", func(t *testing.T) {
+		assert.Equal(t, "<p>see Project B</p>\n", ToHTML("see [[Project B]]\n", HTMLOptions{}))
+	})
+
+	t.Run("Escaped lines and directives", func(t *testing.T) {
+		input := FromSyslang("@meta\ntitle: a\n@end\n* Section\n  # not a heading\n  - [ ] not a task\n")
+		assert.Equal(t, "<dl class=\"meta\">\n<dt>title</dt><dd>a</dd>\n</dl>\n<h1>Section</h1>\n<p># not a heading\n- [ ] not a task</p>\n", ToHTML(input, HTMLOptions{}))
+	})
+
+	t.Run("Only safe link targets are rendered as links", func(t *testing.T) {
+		for input, expected := range map[string]string{
+			"[a](https://example.com)":     `<a href="https://example.com">a</a>`,
+			"[a](mailto:me@example.com)":   `<a href="mailto:me@example.com">a</a>`,
+			"[a](../notes/b.html#top)":     `<a href="../notes/b.html#top">a</a>`,
+			"[a](javascript:alert%281%29)": "a",
+			"[a](JavaScript:alert)":        "a",
+			"[a](java%0ascript:alert)":     "a",
+			"[a](data:text/html,x)":        "a",
+			"[a](vbscript:x)":              "a",
+		} {
+			assert.Equal(t, "<p>"+expected+"</p>\n", ToHTML(input+"\n", HTMLOptions{}), input)
+		}
+	})
+
+	t.Run("Page escapes title", func(t *testing.T) {
+		page := HTMLPage("a <b>", "<p>x</p>\n")
+		assert.True(t, strings.Contains(page, "<title>a &lt;b&gt;</title>"))
+		assert.True(t, strings.Contains(page, "<body>\n<p>x</p>\n</body>"))
+	})
+}
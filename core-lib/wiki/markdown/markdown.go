@@ -0,0 +1,331 @@
+// Package markdown converts between Syslang and the Markdown dialect served by the writable wiki mount.
+//
+// The conversion is line based so that FromSyslang and ToSyslang round-trip:
+//   - the @meta block becomes YAML frontmatter
+//   - sections (`* Title`, `** Title`) become ATX headings, their indented bodies are dedented
+//   - tasks (`[ ] text`) become list items (`- [ ] text`), task properties stay as continuation lines
+//   - @code blocks become fenced code blocks
+//   - body lines that would read as Markdown structure (`#`, `---`, `- [`, fences) are escaped with a backslash
+//   - indentation other than INDENT is recorded as comments in the frontmatter
+//
+// Anything else is copied verbatim.
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const INDENT = "  "
+
+// frontmatter comments recording the indentation of section bodies and of the meta block when it isn't INDENT
+const (
+	INDENT_DIRECTIVE      = "# syslang-indent: "
+	META_INDENT_DIRECTIVE = "# syslang-meta-indent: "
+)
+
+var (
+	syslangSectionRe  = regexp.MustCompile(`^(\s*)(\*+) (.*)$`)
+	syslangTaskRe     = regexp.MustCompile(`^(\s*)(\[[ x\-_]\] .*)$`)
+	syslangCodeRe     = regexp.MustCompile(`^(\s*)@code\s*(.*)$`)
+	markdownHeadingRe = regexp.MustCompile(`^(#+) (.*)$`)
+	markdownTaskRe    = regexp.MustCompile(`^(\s*)- (\[[ x\-_]\] .*)$`)
+	markdownFenceRe   = regexp.MustCompile("^(\\s*)```(.*)$")
+	markdownTableRe   = regexp.MustCompile(`^\s*\|.*\|\s*$`)
+	markdownImageRe   = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownHTMLRe    = regexp.MustCompile(`^\s*<[a-zA-Z/][^>]*>`)
+	markdownQuoteRe   = regexp.MustCompile(`^\s*> `)
+	markdownSetextRe  = regexp.MustCompile(`^(=+|-{3,})\s*$`)
+	markdownEscapeRe  = regexp.MustCompile("^(\\s*)(\\\\*)(#|---|- \\[|```)")
+	markdownEscapedRe = regexp.MustCompile("^(\\s*)\\\\(\\\\*)(#|---|- \\[|```)")
+)
+
+// Issue describes a Markdown construct without a Syslang equivalent, kept verbatim.
+type Issue struct {
+	Line      int
+	Construct string
+	Text      string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%d: %s: %s", i.Line+1, i.Construct, i.Text)
+}
+
+func splitLines(text string) ([]string, bool) {
+	hasTrailingNewline := strings.HasSuffix(text, "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return []string{}, hasTrailingNewline
+	}
+	return strings.Split(text, "\n"), hasTrailingNewline
+}
+
+func joinLines(lines []string, trailingNewline bool) string {
+	result := strings.Join(lines, "\n")
+	if trailingNewline {
+		result += "\n"
+	}
+	return result
+}
+
+// escapeLine adds a backslash to text lines that would otherwise be read back as headings, frontmatter, tasks or fences,
+// lines already starting with backslashes get one more so unescapeLine stays the inverse
+func escapeLine(line string) string {
+	return markdownEscapeRe.ReplaceAllString(line, `$1\$2$3`)
+}
+
+func unescapeLine(line string) string {
+	return markdownEscapedRe.ReplaceAllString(line, `$1$2$3`)
+}
+
+func leadingSpace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// detectIndent returns the indentation of the first section body, a tab or INDENT
+func detectIndent(lines []string) string {
+	for i, line := range lines {
+		match := syslangSectionRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		for _, next := range lines[i+1:] {
+			if strings.TrimSpace(next) == "" {
+				continue
+			}
+			rest := strings.TrimPrefix(next, match[1])
+			if strings.HasPrefix(rest, "\t") {
+				return "\t"
+			}
+			if strings.HasPrefix(rest, " ") {
+				return INDENT
+			}
+			break
+		}
+	}
+	return INDENT
+}
+
+func dedent(line string, prefix string) string {
+	if strings.HasPrefix(line, prefix) {
+		return line[len(prefix):]
+	}
+	return strings.TrimLeft(line, " ")
+}
+
+// FromSyslang converts a Syslang document to Markdown.
+func FromSyslang(text string) string {
+	lines, trailingNewline := splitLines(text)
+	result := []string{}
+
+	indent := detectIndent(lines)
+	bodyIndent := ""
+	inMeta := false
+	metaIndent := ""
+	inCode := false
+	codeIndent := ""
+
+	hasMeta := len(lines) > 0 && strings.TrimSpace(lines[0]) == "@meta"
+	if !hasMeta && indent != INDENT {
+		result = append(result, "---", INDENT_DIRECTIVE+strconv.Quote(indent), "---")
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// meta -> frontmatter
+		if i == 0 && hasMeta {
+			inMeta = true
+			metaIndent = INDENT
+			for _, metaLine := range lines[1:] {
+				if strings.TrimSpace(metaLine) != "" {
+					if strings.TrimSpace(metaLine) != "@end" {
+						metaIndent = leadingSpace(metaLine)
+					}
+					break
+				}
+			}
+			result = append(result, "---")
+			if indent != INDENT {
+				result = append(result, INDENT_DIRECTIVE+strconv.Quote(indent))
+			}
+			if metaIndent != INDENT {
+				result = append(result, META_INDENT_DIRECTIVE+strconv.Quote(metaIndent))
+			}
+			continue
+		}
+		if inMeta {
+			if trimmed == "@end" {
+				inMeta = false
+				result = append(result, "---")
+				continue
+			}
+			result = append(result, strings.TrimPrefix(line, metaIndent))
+			continue
+		}
+
+		// code blocks
+		if inCode {
+			if trimmed == "@end" && strings.HasPrefix(line, codeIndent+"@end") {
+				inCode = false
+				result = append(result, dedent(codeIndent, bodyIndent)+"```")
+				continue
+			}
+			result = append(result, dedent(line, bodyIndent))
+			continue
+		}
+		if match := syslangCodeRe.FindStringSubmatch(line); match != nil {
+			inCode = true
+			codeIndent = match[1]
+			result = append(result, dedent(match[1], bodyIndent)+"```"+match[2])
+			continue
+		}
+
+		// sections
+		if match := syslangSectionRe.FindStringSubmatch(line); match != nil {
+			level := len(match[2])
+			bodyIndent = match[1] + indent
+			result = append(result, strings.Repeat("#", level)+" "+match[3])
+			continue
+		}
+
+		if trimmed == "" {
+			result = append(result, "")
+			continue
+		}
+		line = dedent(line, bodyIndent)
+
+		// tasks
+		if match := syslangTaskRe.FindStringSubmatch(line); match != nil {
+			result = append(result, match[1]+"- "+match[2])
+			continue
+		}
+
+		result = append(result, escapeLine(line))
+	}
+
+	return joinLines(result, trailingNewline)
+}
+
+// ToSyslang converts Markdown produced by FromSyslang (or written by hand) back to Syslang.
+func ToSyslang(text string) (string, []Issue) {
+	lines, trailingNewline := splitLines(text)
+	result := []string{}
+	issues := []Issue{}
+
+	indent := INDENT
+	bodyIndent := ""
+	inFrontmatter := false
+	metaIndent := INDENT
+	metaLines := 0
+	inCode := false
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// frontmatter -> meta
+		if i == 0 && trimmed == "---" {
+			inFrontmatter = true
+			result = append(result, "@meta")
+			continue
+		}
+		if inFrontmatter {
+			if trimmed == "---" {
+				inFrontmatter = false
+				if metaLines == 0 && len(result) == 1 {
+					// the frontmatter only held directives
+					result = result[:0]
+					continue
+				}
+				result = append(result, "@end")
+				continue
+			}
+			if value, ok := strings.CutPrefix(line, INDENT_DIRECTIVE); ok && metaLines == 0 {
+				if unquoted, err := strconv.Unquote(value); err == nil {
+					indent = unquoted
+					continue
+				}
+			}
+			if value, ok := strings.CutPrefix(line, META_INDENT_DIRECTIVE); ok && metaLines == 0 {
+				if unquoted, err := strconv.Unquote(value); err == nil {
+					metaIndent = unquoted
+					continue
+				}
+			}
+			metaLines++
+			if trimmed == "" {
+				result = append(result, "")
+				continue
+			}
+			result = append(result, metaIndent+line)
+			continue
+		}
+
+		// code blocks
+		if match := markdownFenceRe.FindStringSubmatch(line); match != nil {
+			if inCode {
+				inCode = false
+				result = append(result, bodyIndent+match[1]+"@end")
+				continue
+			}
+			inCode = true
+			result = append(result, strings.TrimRight(bodyIndent+match[1]+"@code "+match[2], " "))
+			continue
+		}
+		if inCode {
+			if trimmed == "" {
+				result = append(result, "")
+			} else {
+				result = append(result, bodyIndent+line)
+			}
+			continue
+		}
+
+		// headings -> sections
+		if match := markdownHeadingRe.FindStringSubmatch(line); match != nil {
+			level := len(match[1])
+			sectionIndent := strings.Repeat(indent, level-1)
+			bodyIndent = sectionIndent + indent
+			result = append(result, sectionIndent+strings.Repeat("*", level)+" "+match[2])
+			continue
+		}
+
+		if trimmed == "" {
+			result = append(result, "")
+			continue
+		}
+
+		// unsupported constructs are kept as text
+		switch {
+		case markdownTableRe.MatchString(line):
+			issues = append(issues, Issue{Line: i, Construct: "table", Text: line})
+		case markdownImageRe.MatchString(line):
+			issues = append(issues, Issue{Line: i, Construct: "image", Text: line})
+		case markdownHTMLRe.MatchString(line):
+			issues = append(issues, Issue{Line: i, Construct: "html", Text: line})
+		case markdownQuoteRe.MatchString(line):
+			issues = append(issues, Issue{Line: i, Construct: "blockquote", Text: line})
+		case i > 0 && markdownSetextRe.MatchString(line) && strings.TrimSpace(lines[i-1]) != "":
+			issues = append(issues, Issue{Line: i, Construct: "setext heading", Text: line})
+		}
+
+		// tasks
+		if match := markdownTaskRe.FindStringSubmatch(line); match != nil {
+			result = append(result, bodyIndent+match[1]+match[2])
+			continue
+		}
+
+		result = append(result, bodyIndent+unescapeLine(line))
+	}
+
+	if inFrontmatter {
+		issues = append(issues, Issue{Line: len(lines) - 1, Construct: "frontmatter", Text: "unterminated frontmatter"})
+	}
+	if inCode {
+		issues = append(issues, Issue{Line: len(lines) - 1, Construct: "code", Text: "unterminated code block"})
+	}
+
+	return joinLines(result, trailingNewline), issues
+}
@@ -0,0 +1,61 @@
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readGolden(t *testing.T, name string) string {
+	content, err := os.ReadFile(filepath.Join("../../test-data/markdown", name))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestMarkdown(t *testing.T) {
+	cases := []string{"tasks", "sections", "escapes", "tabs", "meta"}
+
+	for _, name := range cases {
+		t.Run("FromSyslang "+name, func(t *testing.T) {
+			syslang := readGolden(t, name+".syslang")
+			expected := readGolden(t, name+".md")
+			assert.Equal(t, expected, FromSyslang(syslang))
+		})
+
+		t.Run("ToSyslang "+name, func(t *testing.T) {
+			markdown := readGolden(t, name+".md")
+			expected := readGolden(t, name+".syslang")
+			result, issues := ToSyslang(markdown)
+			assert.Equal(t, expected, result)
+			assert.Empty(t, issues)
+		})
+
+		t.Run("Round-trip "+name, func(t *testing.T) {
+			syslang := readGolden(t, name+".syslang")
+			result, issues := ToSyslang(FromSyslang(syslang))
+			assert.Equal(t, syslang, result)
+			assert.Empty(t, issues)
+		})
+	}
+
+	t.Run("Report unsupported constructs", func(t *testing.T) {
+		markdown := "# Title\n| a | b |\n![img](img.png)\n> quote\n<div>html</div>\n"
+		result, issues := ToSyslang(markdown)
+		assert.Equal(t, "* Title\n  | a | b |\n  ![img](img.png)\n  > quote\n  <div>html</div>\n", result)
+		require.Len(t, issues, 4)
+		assert.Equal(t, "table", issues[0].Construct)
+		assert.Equal(t, 1, issues[0].Line)
+		assert.Equal(t, "image", issues[1].Construct)
+		assert.Equal(t, "blockquote", issues[2].Construct)
+		assert.Equal(t, "html", issues[3].Construct)
+	})
+
+	t.Run("Report unterminated blocks", func(t *testing.T) {
+		_, issues := ToSyslang("---\ntitle: x\n")
+		require.Len(t, issues, 1)
+		assert.Equal(t, "frontmatter", issues[0].Construct)
+	})
+}
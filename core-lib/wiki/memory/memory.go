@@ -0,0 +1,264 @@
+// Package memory is a wiki.Wiki kept in memory, meant for tests and tools that don't need files.
+package memory
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/3rd/core/core-lib/wiki"
+	wiki_syslang "github.com/3rd/core/core-lib/wiki/syslang"
+	"github.com/3rd/syslang/go-syslang/pkg/syslang"
+)
+
+// MemoryNode is a fully parsed node, its path is a slash separated key
+type MemoryNode struct {
+	path     string
+	content  string
+	document *syslang.Document
+	links    []wiki.Link
+}
+
+func NewMemoryNode(path string, content string) (*MemoryNode, error) {
+	document, err := syslang.NewDocument(content)
+	if err != nil {
+		return nil, err
+	}
+	return &MemoryNode{
+		path:     path,
+		content:  content,
+		document: document,
+		links:    wiki.ExtractLinks(content),
+	}, nil
+}
+
+// GetID returns the id meta value, falling back to the path
+func (n *MemoryNode) GetID() string {
+	if id := strings.TrimSpace(n.document.GetMeta()[wiki.ID_META_KEY]); id != "" {
+		return id
+	}
+	return n.path
+}
+
+func (n *MemoryNode) GetName() string {
+	if title := n.document.GetTitle(); title != "" {
+		return title
+	}
+	return path.Base(n.path)
+}
+
+func (n *MemoryNode) GetPath() string {
+	return n.path
+}
+
+func (n *MemoryNode) GetMeta() map[string]string {
+	return n.document.GetMeta()
+}
+
+func (n *MemoryNode) GetContent() (string, error) {
+	return n.content, nil
+}
+
+func (n *MemoryNode) GetTasks() []*wiki.Task {
+	return wiki_syslang.NewTasks(n, n.document.GetTasks())
+}
+
+func (n *MemoryNode) GetLinks() []wiki.Link {
+	return n.links
+}
+
+func (n *MemoryNode) ToMarkdown() string {
+	return n.document.ToMarkdown()
+}
+
+// MemoryWiki holds nodes behind mutex, nodes are replaced on writes, never changed in place
+type MemoryWiki struct {
+	mutex     sync.RWMutex
+	nodes     []*MemoryNode
+	backlinks map[string][]*MemoryNode
+}
+
+// NewMemoryWiki creates a wiki from node contents keyed by path
+func NewMemoryWiki(files map[string]string) (*MemoryWiki, error) {
+	w := MemoryWiki{}
+	for path, content := range files {
+		node, err := NewMemoryNode(path, content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		w.nodes = append(w.nodes, node)
+	}
+	w.sort()
+	return &w, nil
+}
+
+var _ wiki.Wiki = (*MemoryWiki)(nil)
+
+func (w *MemoryWiki) sort() {
+	sort.SliceStable(w.nodes, func(i, j int) bool {
+		if w.nodes[i].GetName() != w.nodes[j].GetName() {
+			return w.nodes[i].GetName() < w.nodes[j].GetName()
+		}
+		return w.nodes[i].path < w.nodes[j].path
+	})
+	w.backlinks = nil
+}
+
+func (w *MemoryWiki) GetNodes() ([]wiki.Node, error) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	nodes := make([]wiki.Node, 0, len(w.nodes))
+	for _, node := range w.nodes {
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func (w *MemoryWiki) FindNodes(filter wiki.NodeFilter) ([]wiki.Node, error) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	nodes := []wiki.Node{}
+	for _, node := range w.nodes {
+		if filter(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+func (w *MemoryWiki) FindNode(filter wiki.NodeFilter) (wiki.Node, error) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	for _, node := range w.nodes {
+		if filter(node) {
+			return node, nil
+		}
+	}
+	return nil, nil
+}
+
+func (w *MemoryWiki) getNode(id string) *MemoryNode {
+	for _, node := range w.nodes {
+		if node.GetID() == id {
+			return node
+		}
+	}
+	return nil
+}
+
+func (w *MemoryWiki) GetNode(id string) (wiki.Node, error) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	if node := w.getNode(id); node != nil {
+		return node, nil
+	}
+	return nil, nil
+}
+
+func (w *MemoryWiki) GetNodeByName(name string) (wiki.Node, error) {
+	return w.FindNode(func(node wiki.Node) bool { return node.GetName() == name })
+}
+
+func (w *MemoryWiki) GetBacklinks(id string) ([]wiki.Node, error) {
+	// backlinks are built on first use
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.backlinks == nil {
+		w.backlinks = wiki.BuildBacklinks(w.nodes)
+	}
+	nodes := []wiki.Node{}
+	for _, node := range w.backlinks[id] {
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// Reload is a no-op, memory nodes are always current
+func (w *MemoryWiki) Reload() error {
+	return nil
+}
+
+// CreateNode adds a node, refusing to reuse an existing node name, ID or path,
+// the name comes from the content title or else the path and has to match name
+func (w *MemoryWiki) CreateNode(name string, path string, content string) (wiki.Node, error) {
+	node, err := NewMemoryNode(path, content)
+	if err != nil {
+		return nil, err
+	}
+	if node.GetName() != name {
+		return nil, fmt.Errorf("%w: %q is named %q", wiki.ErrNodeNameMismatch, name, node.GetName())
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for _, existing := range w.nodes {
+		if existing.GetName() == name || existing.GetID() == node.GetID() || existing.path == path {
+			return nil, fmt.Errorf("%w: %s (%s)", wiki.ErrNodeExists, name, existing.path)
+		}
+	}
+	w.nodes = append(w.nodes, node)
+	w.sort()
+	return node, nil
+}
+
+func (w *MemoryWiki) UpdateNode(id string, content string) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	node := w.getNode(id)
+	if node == nil {
+		return fmt.Errorf("node not found: %s", id)
+	}
+	return w.replace(node, content)
+}
+
+// replace swaps a node for a new parse of content, nodes handed out before stay as they were,
+// callers hold the write lock
+func (w *MemoryWiki) replace(node *MemoryNode, content string) error {
+	updated, err := NewMemoryNode(node.path, content)
+	if err != nil {
+		return err
+	}
+	for i := range w.nodes {
+		if w.nodes[i] == node {
+			w.nodes[i] = updated
+		}
+	}
+	w.sort()
+	return nil
+}
+
+// editTask applies a Syslang edit to the node of a task
+func (w *MemoryWiki) editTask(task *wiki.Task, edit func(text string) (string, error)) error {
+	node, ok := task.Node.(*MemoryNode)
+	if !ok || node == nil {
+		return fmt.Errorf("task %q doesn't belong to a memory node", task.Text)
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	text, err := edit(node.content)
+	if err != nil {
+		return err
+	}
+	return w.replace(node, text)
+}
+
+func (w *MemoryWiki) StartTaskSession(task *wiki.Task, now time.Time) error {
+	return w.editTask(task, func(text string) (string, error) {
+		return wiki.StartTaskSessionText(text, task, now), nil
+	})
+}
+
+func (w *MemoryWiki) StopTaskSession(task *wiki.Task, now time.Time) error {
+	return w.editTask(task, func(text string) (string, error) {
+		return wiki.StopTaskSessionText(text, task, now)
+	})
+}
+
+func (w *MemoryWiki) ToggleTaskDone(task *wiki.Task, now time.Time) error {
+	return w.editTask(task, func(text string) (string, error) {
+		return wiki.ToggleTaskDoneText(text, task, now), nil
+	})
+}
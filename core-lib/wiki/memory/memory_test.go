@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/3rd/core/core-lib/wiki"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryWiki(t *testing.T) {
+	newWiki := func(t *testing.T) *MemoryWiki {
+		memoryWiki, err := NewMemoryWiki(map[string]string{
+			"projects/alpha": "@meta\ntitle: Alpha\ntype: project\n@end\n* Tasks\n  [-] plan\n  See [[notes]].\n",
+			"notes":          "@meta\nid: notes-id\n@end\n* Notes\n",
+		})
+		require.NoError(t, err)
+		return memoryWiki
+	}
+
+	t.Run("IDs, names and lookups", func(t *testing.T) {
+		memoryWiki := newWiki(t)
+
+		node, err := memoryWiki.GetNode("projects/alpha")
+		require.NoError(t, err)
+		require.NotNil(t, node)
+		assert.Equal(t, "Alpha", node.GetName())
+		assert.Equal(t, "projects/alpha", node.GetPath())
+
+		node, err = memoryWiki.GetNodeByName("notes")
+		require.NoError(t, err)
+		require.NotNil(t, node)
+		assert.Equal(t, "notes-id", node.GetID())
+
+		node, err = memoryWiki.GetNode("missing")
+		require.NoError(t, err)
+		assert.Nil(t, node)
+
+		projects, err := memoryWiki.FindNodes(wiki.MetaIn("type", "project"))
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		assert.Equal(t, "Alpha", projects[0].GetName())
+	})
+
+	t.Run("Backlinks", func(t *testing.T) {
+		memoryWiki := newWiki(t)
+		backlinks, err := memoryWiki.GetBacklinks("notes-id")
+		require.NoError(t, err)
+		require.Len(t, backlinks, 1)
+		assert.Equal(t, "projects/alpha", backlinks[0].GetID())
+	})
+
+	t.Run("Create and update nodes", func(t *testing.T) {
+		memoryWiki := newWiki(t)
+
+		_, err := memoryWiki.CreateNode("Alpha", "alpha-2", "@meta\ntitle: Alpha\n@end\n")
+		assert.ErrorIs(t, err, wiki.ErrNodeExists)
+
+		_, err = memoryWiki.CreateNode("Foo", "x", "")
+		assert.ErrorIs(t, err, wiki.ErrNodeNameMismatch)
+		node, err := memoryWiki.GetNode("x")
+		require.NoError(t, err)
+		assert.Nil(t, node)
+
+		node, err = memoryWiki.CreateNode("Beta", "projects/beta", "@meta\ntitle: Beta\n@end\n")
+		require.NoError(t, err)
+		assert.Equal(t, "projects/beta", node.GetID())
+
+		require.NoError(t, memoryWiki.UpdateNode("projects/beta", "@meta\ntitle: Beta\ntype: project\n@end\n"))
+		node, err = memoryWiki.GetNode("projects/beta")
+		require.NoError(t, err)
+		assert.Equal(t, "project", node.GetMeta()["type"])
+
+		assert.Error(t, memoryWiki.UpdateNode("missing", ""))
+	})
+
+	t.Run("Create checks the ID of the new node", func(t *testing.T) {
+		memoryWiki := newWiki(t)
+
+		// the id meta collides even though the path is new
+		_, err := memoryWiki.CreateNode("Other", "other", "@meta\ntitle: Other\nid: notes-id\n@end\n")
+		assert.ErrorIs(t, err, wiki.ErrNodeExists)
+
+		// a path that matches an existing ID is fine when the node has its own id
+		node, err := memoryWiki.CreateNode("Fresh", "notes-id", "@meta\ntitle: Fresh\nid: fresh\n@end\n")
+		require.NoError(t, err)
+		assert.Equal(t, "fresh", node.GetID())
+	})
+
+	t.Run("Concurrent reads and writes", func(t *testing.T) {
+		memoryWiki := newWiki(t)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, memoryWiki.UpdateNode("notes-id", fmt.Sprintf("@meta\nid: notes-id\n@end\n* Notes %d\n", i)))
+				_, err := memoryWiki.CreateNode(fmt.Sprintf("node-%d", i), fmt.Sprintf("node-%d", i), "")
+				assert.NoError(t, err)
+			}(i)
+			go func() {
+				defer wg.Done()
+				_, err := memoryWiki.GetNodes()
+				assert.NoError(t, err)
+				_, err = memoryWiki.GetBacklinks("notes-id")
+				assert.NoError(t, err)
+				_, err = memoryWiki.GetNodeByName("Alpha")
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		nodes, err := memoryWiki.GetNodes()
+		require.NoError(t, err)
+		assert.Len(t, nodes, 12)
+	})
+
+	t.Run("Task hooks", func(t *testing.T) {
+		memoryWiki := newWiki(t)
+		now := time.Date(2024, 1, 2, 10, 30, 0, 0, time.Local)
+
+		node, err := memoryWiki.GetNode("projects/alpha")
+		require.NoError(t, err)
+		require.NoError(t, memoryWiki.StartTaskSession(node.GetTasks()[0], now))
+
+		node, err = memoryWiki.GetNode("projects/alpha")
+		require.NoError(t, err)
+		tasks := node.GetTasks()
+		require.Len(t, tasks, 1)
+		require.Len(t, tasks[0].Sessions, 1)
+		assert.True(t, tasks[0].Sessions[0].IsInProgress())
+
+		require.NoError(t, memoryWiki.StopTaskSession(tasks[0], now.Add(time.Hour)))
+		node, err = memoryWiki.GetNode("projects/alpha")
+		require.NoError(t, err)
+		require.NoError(t, memoryWiki.ToggleTaskDone(node.GetTasks()[0], now.Add(time.Hour)))
+
+		node, err = memoryWiki.GetNode("projects/alpha")
+		require.NoError(t, err)
+		content, err := node.GetContent()
+		require.NoError(t, err)
+		assert.Equal(t, "@meta\ntitle: Alpha\ntype: project\n@end\n* Tasks\n  [x] plan\n    Session: 2024.01.02 10:30-11:30\n  See [[notes]].\n", content)
+	})
+}
@@ -0,0 +1,45 @@
+package wiki
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// META_DATE_LAYOUT is the Syslang date format, ISO dates are accepted too
+const META_DATE_LAYOUT = "2006.01.02"
+
+var metaDateLayouts = []string{META_DATE_LAYOUT, "2006-01-02"}
+
+// GetMetaValue returns a trimmed meta value of a node and whether it's set
+func GetMetaValue(node Node, key string) (string, bool) {
+	meta := node.GetMeta()
+	if meta == nil {
+		return "", false
+	}
+	value, ok := meta[key]
+	value = strings.TrimSpace(value)
+	return value, ok && value != ""
+}
+
+// ParseMetaList splits a comma separated meta value, skipping empty items
+func ParseMetaList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ParseMetaDate parses a meta date in local time
+func ParseMetaDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range metaDateLayouts {
+		if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (expected %s)", value, META_DATE_LAYOUT)
+}
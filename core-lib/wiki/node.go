@@ -1,11 +1,18 @@
 package wiki
 
+// ID_META_KEY holds an explicit node ID that survives renames and moves
+const ID_META_KEY = "id"
+
 type Node interface {
 	GetID() string
 	GetName() string
+	// GetPath returns where the node is stored, a file path for local nodes
+	GetPath() string
 	GetMeta() map[string]string
 	GetContent() (string, error)
 	GetTasks() []*Task
+	GetLinks() []Link
+	ToMarkdown() string
 }
 
 type NodeFilter func(node Node) bool
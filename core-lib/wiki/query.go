@@ -0,0 +1,106 @@
+package wiki
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// operators in matching order at a position, longer operators first
+var queryOperators = []string{
+	OPERATOR_NOT_EQUAL,
+	OPERATOR_NOT_CONTAINS,
+	OPERATOR_LESS_EQUAL,
+	OPERATOR_GREATER_EQUAL,
+	OPERATOR_EQUAL,
+	OPERATOR_CONTAINS,
+	OPERATOR_LESS,
+	OPERATOR_GREATER,
+}
+
+// splitQuery splits on whitespace outside double quotes, dropping the quotes
+func splitQuery(query string) ([]string, error) {
+	clauses := []string{}
+	current := strings.Builder{}
+	inQuotes := false
+	hasClause := false
+	for _, char := range query {
+		switch {
+		case char == '"':
+			inQuotes = !inQuotes
+			hasClause = true
+		case !inQuotes && (char == ' ' || char == '\t' || char == '\n'):
+			if hasClause {
+				clauses = append(clauses, current.String())
+				current.Reset()
+				hasClause = false
+			}
+		default:
+			current.WriteRune(char)
+			hasClause = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in query: %s", query)
+	}
+	if hasClause {
+		clauses = append(clauses, current.String())
+	}
+	return clauses, nil
+}
+
+// findQueryOperator returns the first operator of a clause, -1 when there's none
+func findQueryOperator(clause string) (int, string) {
+	for index := 1; index < len(clause); index++ {
+		for _, operator := range queryOperators {
+			if strings.HasPrefix(clause[index:], operator) {
+				return index, operator
+			}
+		}
+	}
+	return -1, ""
+}
+
+func parseQueryClause(clause string, now time.Time) (NodeFilter, error) {
+	if index, operator := findQueryOperator(clause); index != -1 {
+		key := strings.TrimSpace(clause[:index])
+		value := strings.TrimSpace(clause[index+len(operator):])
+		switch operator {
+		case OPERATOR_EQUAL:
+			return MetaIn(key, ParseMetaList(value)...), nil
+		case OPERATOR_NOT_EQUAL:
+			return Not(MetaIn(key, ParseMetaList(value)...)), nil
+		case OPERATOR_CONTAINS:
+			return MetaContains(key, value), nil
+		case OPERATOR_NOT_CONTAINS:
+			return Not(MetaContains(key, value)), nil
+		}
+		return MetaCompare(key, operator, ResolveMetaDate(value, now)), nil
+	}
+	if strings.HasPrefix(clause, "=") || strings.HasPrefix(clause, "<") || strings.HasPrefix(clause, ">") || strings.HasPrefix(clause, "~") {
+		return nil, fmt.Errorf("missing key in query clause: %s", clause)
+	}
+	if strings.HasPrefix(clause, "!") {
+		return Not(MetaExists(clause[1:])), nil
+	}
+	return MetaExists(clause), nil
+}
+
+// ParseQuery builds a filter matching every clause of a meta query, e.g.
+// `type=project,person status!=archived owner~alice due<=today+7d !parent`,
+// = and != accept comma separated alternatives and ordering operators compare dates and numbers
+func ParseQuery(query string, now time.Time) (NodeFilter, error) {
+	clauses, err := splitQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	filters := []NodeFilter{}
+	for _, clause := range clauses {
+		filter, err := parseQueryClause(clause, now)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return And(filters...), nil
+}
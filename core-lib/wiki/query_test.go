@@ -0,0 +1,63 @@
+package wiki_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/3rd/core/core-lib/wiki"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.Local)
+	nodes := getTestNodes(t, map[string]string{
+		"alpha": "@meta\ntype: project\nstatus: active\nowner: Alice Smith\ndue: 2024.03.12\npriority: 10\n@end\n",
+		"beta":  "@meta\ntype: project\nstatus: archived\nowner: alice\ndue: 2024.02.01\npriority: 9\n@end\n",
+		"jane":  "@meta\ntype: person\n@end\n",
+		"loose": "",
+	})
+	query := func(t *testing.T, text string) []string {
+		filter, err := wiki.ParseQuery(text, now)
+		require.NoError(t, err)
+		ids := []string{}
+		for _, node := range nodes {
+			if filter(node) {
+				ids = append(ids, node.GetID())
+			}
+		}
+		return ids
+	}
+
+	t.Run("Equality and containment", func(t *testing.T) {
+		assert.Equal(t, []string{"alpha"}, query(t, "type=project status!=archived owner~alice"))
+		assert.Equal(t, []string{"alpha", "beta", "jane"}, query(t, "type=project,person"))
+		assert.Equal(t, []string{"beta", "jane", "loose"}, query(t, `owner!~"alice smith"`))
+		assert.Equal(t, []string{"alpha", "beta", "jane", "loose"}, query(t, ""))
+	})
+
+	t.Run("Existence", func(t *testing.T) {
+		assert.Equal(t, []string{"alpha", "beta"}, query(t, "due"))
+		assert.Equal(t, []string{"jane", "loose"}, query(t, "!due"))
+	})
+
+	t.Run("Dates and numbers", func(t *testing.T) {
+		assert.Equal(t, []string{"alpha"}, query(t, "due>=today due<=today+1w"))
+		assert.Equal(t, []string{"beta"}, query(t, "due<2024-03-01"))
+		assert.Equal(t, []string{"alpha"}, query(t, "priority>9"))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := wiki.ParseQuery(`owner~"alice`, now)
+		assert.Error(t, err)
+		_, err = wiki.ParseQuery("=project", now)
+		assert.Error(t, err)
+	})
+
+	t.Run("ResolveMetaDate", func(t *testing.T) {
+		assert.Equal(t, "2024.03.10", wiki.ResolveMetaDate("today", now))
+		assert.Equal(t, "2024.03.07", wiki.ResolveMetaDate("today-3d", now))
+		assert.Equal(t, "2024.03.24", wiki.ResolveMetaDate("today+2w", now))
+		assert.Equal(t, "today+x", wiki.ResolveMetaDate("today+x", now))
+	})
+}
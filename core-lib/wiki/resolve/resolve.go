@@ -0,0 +1,149 @@
+// Package resolve finds nodes by name, file name or alias, exactly or fuzzily.
+package resolve
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/3rd/core/core-lib/wiki"
+)
+
+// ALIASES_META_KEY holds comma separated alternative names of a node
+const ALIASES_META_KEY = "aliases"
+
+// fields a candidate can match on
+const (
+	FIELD_NAME  = "name"
+	FIELD_FILE  = "file"
+	FIELD_ALIAS = "alias"
+)
+
+// scoring weights
+const (
+	SCORE_MATCH       = 1
+	SCORE_CONSECUTIVE = 5
+	SCORE_BOUNDARY    = 8
+	SCORE_PREFIX      = 10
+	SCORE_EXACT       = 1000
+)
+
+type Candidate[T wiki.Node] struct {
+	Node  T
+	Field string
+	Match string
+	Score int
+}
+
+func getAliases(node wiki.Node) []string {
+	return wiki.ParseMetaList(node.GetMeta()[ALIASES_META_KEY])
+}
+
+// getFields returns the texts a node can be found by, in priority order
+func getFields(node wiki.Node) [][2]string {
+	fields := [][2]string{{FIELD_NAME, node.GetName()}, {FIELD_FILE, filepath.Base(node.GetPath())}}
+	for _, alias := range getAliases(node) {
+		fields = append(fields, [2]string{FIELD_ALIAS, alias})
+	}
+	return fields
+}
+
+func isBoundary(text []rune, index int) bool {
+	if index == 0 {
+		return true
+	}
+	previous := text[index-1]
+	return !unicode.IsLetter(previous) && !unicode.IsNumber(previous) ||
+		unicode.IsLower(previous) && unicode.IsUpper(text[index])
+}
+
+// Score rates how well query matches text as a case-insensitive subsequence, 0 means no match
+func Score(query string, text string) int {
+	if query == "" {
+		return 0
+	}
+	if strings.EqualFold(query, text) {
+		return SCORE_EXACT
+	}
+
+	queryRunes := []rune(strings.ToLower(query))
+	textRunes := []rune(text)
+	lowerRunes := []rune(strings.ToLower(text))
+	if len(lowerRunes) != len(textRunes) {
+		textRunes = lowerRunes
+	}
+
+	score := 0
+	q := 0
+	previous := -2
+	for i := 0; i < len(lowerRunes) && q < len(queryRunes); i++ {
+		if lowerRunes[i] != queryRunes[q] {
+			continue
+		}
+		score += SCORE_MATCH
+		if i == previous+1 {
+			score += SCORE_CONSECUTIVE
+		}
+		if isBoundary(textRunes, i) {
+			score += SCORE_BOUNDARY
+		}
+		previous = i
+		q++
+	}
+	if q < len(queryRunes) {
+		return 0
+	}
+	if strings.HasPrefix(string(lowerRunes), string(queryRunes)) {
+		score += SCORE_PREFIX
+	}
+	// prefer shorter texts among equal matches
+	score -= (len(lowerRunes) - len(queryRunes)) / 4
+	if score < 1 {
+		score = 1
+	}
+	return score
+}
+
+// Exact returns the node whose ID, name, file name or alias equals target, in that order of precedence
+func Exact[T wiki.Node](nodes []T, target string) (T, bool) {
+	for _, node := range nodes {
+		if node.GetID() == target {
+			return node, true
+		}
+	}
+	for _, field := range []string{FIELD_NAME, FIELD_FILE, FIELD_ALIAS} {
+		for _, node := range nodes {
+			for _, candidate := range getFields(node) {
+				if candidate[0] == field && candidate[1] == target {
+					return node, true
+				}
+			}
+		}
+	}
+	var empty T
+	return empty, false
+}
+
+// Rank returns the nodes matching query, best first, an empty query matches every node
+func Rank[T wiki.Node](nodes []T, query string) []Candidate[T] {
+	candidates := []Candidate[T]{}
+	for _, node := range nodes {
+		best := Candidate[T]{Node: node, Field: FIELD_NAME, Match: node.GetName()}
+		for _, field := range getFields(node) {
+			if score := Score(query, field[1]); score > best.Score {
+				best = Candidate[T]{Node: node, Field: field[0], Match: field[1], Score: score}
+			}
+		}
+		if best.Score > 0 || query == "" {
+			candidates = append(candidates, best)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Node.GetName() < candidates[j].Node.GetName()
+	})
+	return candidates
+}
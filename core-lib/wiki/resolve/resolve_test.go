@@ -0,0 +1,56 @@
+package resolve
+
+import (
+	"testing"
+
+	"github.com/3rd/core/core-lib/wiki/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	memoryWiki, err := memory.NewMemoryWiki(map[string]string{
+		"projects/alpha": "@meta\ntitle: Project Alpha\naliases: pa, alpha project\n@end\n",
+		"programming":    "@meta\ntitle: Programming\n@end\n",
+		"notes":          "@meta\ntitle: Notes\n@end\n",
+	})
+	require.NoError(t, err)
+	nodes, err := memoryWiki.GetNodes()
+	require.NoError(t, err)
+
+	t.Run("Score", func(t *testing.T) {
+		assert.Equal(t, 0, Score("xyz", "Project Alpha"))
+		assert.Equal(t, SCORE_EXACT, Score("project alpha", "Project Alpha"))
+		assert.Greater(t, Score("pa", "Project Alpha"), Score("pa", "Programming"))
+		assert.Greater(t, Score("prog", "Programming"), Score("prog", "Project Alpha"))
+	})
+
+	t.Run("Exact by name, file or alias", func(t *testing.T) {
+		node, ok := Exact(nodes, "Notes")
+		require.True(t, ok)
+		assert.Equal(t, "Notes", node.GetName())
+
+		node, ok = Exact(nodes, "alpha")
+		require.True(t, ok)
+		assert.Equal(t, "Project Alpha", node.GetName())
+
+		node, ok = Exact(nodes, "alpha project")
+		require.True(t, ok)
+		assert.Equal(t, "Project Alpha", node.GetName())
+
+		_, ok = Exact(nodes, "NOTES")
+		assert.False(t, ok)
+	})
+
+	t.Run("Rank candidates", func(t *testing.T) {
+		candidates := Rank(nodes, "pa")
+		require.Len(t, candidates, 2)
+		assert.Equal(t, "Project Alpha", candidates[0].Node.GetName())
+		assert.Equal(t, FIELD_ALIAS, candidates[0].Field)
+		assert.Equal(t, "Programming", candidates[1].Node.GetName())
+	})
+
+	t.Run("Empty query lists every node", func(t *testing.T) {
+		assert.Len(t, Rank(nodes, ""), 3)
+	})
+}
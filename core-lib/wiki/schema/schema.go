@@ -0,0 +1,213 @@
+// Package schema validates node meta against per-type definitions.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/3rd/core/core-lib/wiki"
+)
+
+// SCHEMA_FILE is read from the wiki root when present
+const SCHEMA_FILE = ".schema.json"
+
+// field types
+const (
+	FIELD_TYPE_STRING    = "string"
+	FIELD_TYPE_ENUM      = "enum"
+	FIELD_TYPE_DATE      = "date"
+	FIELD_TYPE_LIST      = "list"
+	FIELD_TYPE_REFERENCE = "reference"
+)
+
+var FieldTypes = []string{FIELD_TYPE_STRING, FIELD_TYPE_ENUM, FIELD_TYPE_DATE, FIELD_TYPE_LIST, FIELD_TYPE_REFERENCE}
+
+// Field describes a meta key, list items are validated as Items (string by default)
+type Field struct {
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Values   []string `json:"values"`
+	Items    string   `json:"items"`
+	// NodeType restricts references to nodes of a type
+	NodeType string `json:"nodeType"`
+}
+
+// TypeSchema maps meta keys to their definition, keys not listed accept anything
+type TypeSchema map[string]Field
+
+// Schema maps node types to their definition, nodes of other types aren't validated
+type Schema map[string]TypeSchema
+
+func checkFieldType(fieldType string, allowList bool) error {
+	if fieldType == FIELD_TYPE_LIST && !allowList {
+		return fmt.Errorf("nested lists are not supported")
+	}
+	if !slices.Contains(FieldTypes, fieldType) {
+		return fmt.Errorf("invalid field type: %s", fieldType)
+	}
+	return nil
+}
+
+// LoadSchema reads a schema file, a missing file yields an empty schema
+func LoadSchema(path string) (Schema, error) {
+	schema := Schema{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return schema, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+	for nodeType, typeSchema := range schema {
+		for key, field := range typeSchema {
+			if field.Type == "" {
+				field.Type = FIELD_TYPE_STRING
+			}
+			if field.Type == FIELD_TYPE_LIST && field.Items == "" {
+				field.Items = FIELD_TYPE_STRING
+			}
+			if err := checkFieldType(field.Type, true); err != nil {
+				return nil, fmt.Errorf("invalid schema %s: %s.%s: %w", path, nodeType, key, err)
+			}
+			if field.Type == FIELD_TYPE_LIST {
+				if err := checkFieldType(field.Items, false); err != nil {
+					return nil, fmt.Errorf("invalid schema %s: %s.%s: %w", path, nodeType, key, err)
+				}
+			}
+			if (field.Type == FIELD_TYPE_ENUM || field.Items == FIELD_TYPE_ENUM) && len(field.Values) == 0 {
+				return nil, fmt.Errorf("invalid schema %s: %s.%s: enum without values", path, nodeType, key)
+			}
+			typeSchema[key] = field
+		}
+	}
+	return schema, nil
+}
+
+// Issue is a validation error, Line and Column are 1-based and point at the meta key when it's set
+type Issue struct {
+	Path    string
+	Line    int
+	Column  int
+	Key     string
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", i.Path, i.Line, i.Column, i.Key, i.Message)
+}
+
+// findReference resolves a reference by node name or ID
+func findReference(wikiInstance wiki.Wiki, target string) (wiki.Node, error) {
+	node, err := wikiInstance.GetNodeByName(target)
+	if err != nil || node != nil {
+		return node, err
+	}
+	return wikiInstance.GetNode(target)
+}
+
+func validateValue(wikiInstance wiki.Wiki, field Field, fieldType string, value string) (string, error) {
+	switch fieldType {
+	case FIELD_TYPE_ENUM:
+		if !slices.Contains(field.Values, value) {
+			return fmt.Sprintf("unknown value %q (expected one of %s)", value, strings.Join(field.Values, ", ")), nil
+		}
+	case FIELD_TYPE_DATE:
+		if _, err := wiki.ParseMetaDate(value); err != nil {
+			return err.Error(), nil
+		}
+	case FIELD_TYPE_REFERENCE:
+		target, err := findReference(wikiInstance, value)
+		if err != nil {
+			return "", err
+		}
+		if target == nil {
+			return fmt.Sprintf("unknown node %q", value), nil
+		}
+		if field.NodeType != "" && target.GetMeta()["type"] != field.NodeType {
+			return fmt.Sprintf("node %q is not a %s", value, field.NodeType), nil
+		}
+	}
+	return "", nil
+}
+
+// ValidateNode checks the meta of a node against the schema of its type
+func ValidateNode(wikiInstance wiki.Wiki, schema Schema, node wiki.Node) ([]Issue, error) {
+	issues := []Issue{}
+	nodeType, _ := wiki.GetMetaValue(node, "type")
+	typeSchema, ok := schema[nodeType]
+	if !ok {
+		return issues, nil
+	}
+	text, err := node.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(text, "\n")
+
+	keys := []string{}
+	for key := range typeSchema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field := typeSchema[key]
+		line, column := wiki.FindMetaKey(lines, key)
+		value, ok := wiki.GetMetaValue(node, key)
+		if !ok {
+			if field.Required {
+				issues = append(issues, Issue{Path: node.GetPath(), Line: line, Column: column, Key: key, Message: fmt.Sprintf("missing required %s", key)})
+			}
+			continue
+		}
+
+		values := []string{value}
+		fieldType := field.Type
+		if field.Type == FIELD_TYPE_LIST {
+			values = wiki.ParseMetaList(value)
+			fieldType = field.Items
+		}
+		for _, item := range values {
+			message, err := validateValue(wikiInstance, field, fieldType, item)
+			if err != nil {
+				return nil, err
+			}
+			if message != "" {
+				issues = append(issues, Issue{Path: node.GetPath(), Line: line, Column: column, Key: key, Message: message})
+			}
+		}
+	}
+	return issues, nil
+}
+
+// Validate checks every node with a typed schema, issues are sorted by position
+func Validate(wikiInstance wiki.Wiki, schema Schema) ([]Issue, error) {
+	nodes, err := wikiInstance.GetNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	issues := []Issue{}
+	for _, node := range nodes {
+		nodeIssues, err := ValidateNode(wikiInstance, schema, node)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, nodeIssues...)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Path != issues[j].Path {
+			return issues[i].Path < issues[j].Path
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues, nil
+}
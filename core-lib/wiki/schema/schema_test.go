@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/3rd/core/core-lib/wiki/local"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	root, err := filepath.Abs("../../test-data/wiki/schema")
+	require.NoError(t, err)
+
+	t.Run("LoadSchema", func(t *testing.T) {
+		schema, err := LoadSchema(filepath.Join(root, SCHEMA_FILE))
+		require.NoError(t, err)
+		assert.Equal(t, FIELD_TYPE_STRING, schema["person"]["email"].Type)
+		assert.Equal(t, FIELD_TYPE_REFERENCE, schema["project"]["members"].Items)
+
+		schema, err = LoadSchema(filepath.Join(root, "missing.json"))
+		require.NoError(t, err)
+		assert.Empty(t, schema)
+
+		invalidPath := filepath.Join(t.TempDir(), SCHEMA_FILE)
+		require.NoError(t, os.WriteFile(invalidPath, []byte(`{"project": {"due": {"type": "time"}}}`), 0o644))
+		_, err = LoadSchema(invalidPath)
+		assert.Error(t, err)
+
+		require.NoError(t, os.WriteFile(invalidPath, []byte(`{"project": {"status": {"type": "enum"}}}`), 0o644))
+		_, err = LoadSchema(invalidPath)
+		assert.ErrorContains(t, err, "enum without values")
+
+		require.NoError(t, os.WriteFile(invalidPath, []byte(`{"project": {"tags": {"type": "list", "items": "enum", "values": []}}}`), 0o644))
+		_, err = LoadSchema(invalidPath)
+		assert.ErrorContains(t, err, "enum without values")
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		schema, err := LoadSchema(filepath.Join(root, SCHEMA_FILE))
+		require.NoError(t, err)
+		wikiInstance, err := local.NewLocalWiki(local.LocalWikiConfig{Root: root, Parse: local.PARSE_MODE_META})
+		require.NoError(t, err)
+
+		issues, err := Validate(wikiInstance, schema)
+		require.NoError(t, err)
+		lines := []string{}
+		for _, issue := range issues {
+			lines = append(lines, issue.String())
+		}
+		betaPath := filepath.Join(root, "projects", "beta")
+		assert.Equal(t, []string{
+			betaPath + `:1:1: status: missing required status`,
+			betaPath + `:4:3: due: invalid date "soon" (expected 2006.01.02)`,
+			betaPath + `:5:3: owner: node "Alpha" is not a person`,
+			betaPath + `:6:3: members: unknown node "Bob"`,
+		}, lines)
+	})
+}
@@ -0,0 +1,286 @@
+// Package search is an in-memory inverted index over wiki nodes with BM25 ranking.
+package search
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+type FIELD string
+
+// fields a line can belong to, queries can be scoped to one of them
+const (
+	FIELD_TEXT FIELD = "text"
+	FIELD_META FIELD = "meta"
+	FIELD_TASK FIELD = "task"
+)
+
+// BM25 parameters
+const (
+	BM25_K1 = 1.2
+	BM25_B  = 0.75
+)
+
+const SNIPPET_LENGTH = 120
+
+var taskLineRe = regexp.MustCompile(`^\s*\[[ x\-_]\] `)
+
+type line struct {
+	field  FIELD
+	text   string
+	tokens []string
+}
+
+type document struct {
+	path   string
+	id     string
+	lines  []line
+	length int
+}
+
+// occurrence is the position of a term or phrase in a document
+type occurrence struct {
+	line  int
+	token int
+}
+
+// Index maps terms to the documents and lines they occur in, it's safe for concurrent use
+type Index struct {
+	mutex       sync.RWMutex
+	documents   map[string]*document
+	postings    map[string]map[string][]occurrence
+	totalLength int
+}
+
+type Result struct {
+	Path    string
+	NodeID  string
+	Score   float64
+	Line    int
+	Snippet string
+}
+
+func NewIndex() *Index {
+	return &Index{
+		documents: map[string]*document{},
+		postings:  map[string]map[string][]occurrence{},
+	}
+}
+
+// Tokenize splits text into lowercase words
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func splitDocument(text string) []line {
+	lines := []line{}
+	inMeta := false
+	for i, text := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(text)
+		field := FIELD_TEXT
+		switch {
+		case i == 0 && trimmed == "@meta":
+			inMeta = true
+			field = FIELD_META
+		case inMeta:
+			field = FIELD_META
+			if trimmed == "@end" {
+				inMeta = false
+			}
+		case taskLineRe.MatchString(text):
+			field = FIELD_TASK
+		}
+		lines = append(lines, line{field: field, text: text, tokens: Tokenize(text)})
+	}
+	return lines
+}
+
+func (idx *Index) remove(path string) {
+	doc, exists := idx.documents[path]
+	if !exists {
+		return
+	}
+	for _, line := range doc.lines {
+		for _, token := range line.tokens {
+			if postings, ok := idx.postings[token]; ok {
+				delete(postings, path)
+				if len(postings) == 0 {
+					delete(idx.postings, token)
+				}
+			}
+		}
+	}
+	idx.totalLength -= doc.length
+	delete(idx.documents, path)
+}
+
+// Add indexes a node, replacing a previous version of the same path
+func (idx *Index) Add(path string, id string, text string) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.remove(path)
+	doc := document{path: path, id: id, lines: splitDocument(text)}
+	for i, line := range doc.lines {
+		for j, token := range line.tokens {
+			postings, ok := idx.postings[token]
+			if !ok {
+				postings = map[string][]occurrence{}
+				idx.postings[token] = postings
+			}
+			postings[path] = append(postings[path], occurrence{line: i, token: j})
+		}
+		doc.length += len(line.tokens)
+	}
+	idx.documents[path] = &doc
+	idx.totalLength += doc.length
+}
+
+// Remove drops a node from the index
+func (idx *Index) Remove(path string) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.remove(path)
+}
+
+func (idx *Index) Len() int {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	return len(idx.documents)
+}
+
+func (idx *Index) inScope(doc *document, occ occurrence, field FIELD) bool {
+	return field == "" || doc.lines[occ.line].field == field
+}
+
+// match returns the occurrences of a clause per document
+func (idx *Index) match(c clause) map[string][]occurrence {
+	result := map[string][]occurrence{}
+
+	// single terms and prefixes
+	if len(c.terms) == 1 {
+		terms := []string{c.terms[0]}
+		if c.prefix {
+			terms = []string{}
+			for term := range idx.postings {
+				if strings.HasPrefix(term, c.terms[0]) {
+					terms = append(terms, term)
+				}
+			}
+		}
+		for _, term := range terms {
+			for path, occurrences := range idx.postings[term] {
+				doc := idx.documents[path]
+				for _, occ := range occurrences {
+					if idx.inScope(doc, occ, c.field) {
+						result[path] = append(result[path], occ)
+					}
+				}
+			}
+		}
+		return result
+	}
+
+	// phrases, within a single line
+	for path, occurrences := range idx.postings[c.terms[0]] {
+		doc := idx.documents[path]
+		for _, occ := range occurrences {
+			if !idx.inScope(doc, occ, c.field) {
+				continue
+			}
+			tokens := doc.lines[occ.line].tokens
+			if occ.token+len(c.terms) > len(tokens) {
+				continue
+			}
+			matches := true
+			for k, term := range c.terms[1:] {
+				if tokens[occ.token+k+1] != term {
+					matches = false
+					break
+				}
+			}
+			if matches {
+				result[path] = append(result[path], occ)
+			}
+		}
+	}
+	return result
+}
+
+// getSnippet shortens a line to SNIPPET_LENGTH runes
+func getSnippet(text string) string {
+	text = strings.TrimSpace(text)
+	if runes := []rune(text); len(runes) > SNIPPET_LENGTH {
+		return string(runes[:SNIPPET_LENGTH]) + "…"
+	}
+	return text
+}
+
+// Search returns the documents matching every clause of the query, best BM25 score first
+func (idx *Index) Search(query string, limit int) []Result {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	clauses := parseQuery(query)
+	if len(clauses) == 0 || len(idx.documents) == 0 {
+		return []Result{}
+	}
+
+	matches := []map[string][]occurrence{}
+	for _, c := range clauses {
+		matches = append(matches, idx.match(c))
+	}
+
+	documentCount := float64(len(idx.documents))
+	averageLength := float64(idx.totalLength) / documentCount
+
+	results := []Result{}
+	for path, doc := range idx.documents {
+		score := 0.0
+		firstLine := -1
+		for _, match := range matches {
+			occurrences, ok := match[path]
+			if !ok {
+				score = -1
+				break
+			}
+			documentFrequency := float64(len(match))
+			idf := math.Log(1 + (documentCount-documentFrequency+0.5)/(documentFrequency+0.5))
+			tf := float64(len(occurrences))
+			norm := 1 - BM25_B + BM25_B*float64(doc.length)/averageLength
+			score += idf * tf * (BM25_K1 + 1) / (tf + BM25_K1*norm)
+			for _, occ := range occurrences {
+				if firstLine == -1 || occ.line < firstLine {
+					firstLine = occ.line
+				}
+			}
+		}
+		if score < 0 {
+			continue
+		}
+		results = append(results, Result{
+			Path:    path,
+			NodeID:  doc.id,
+			Score:   score,
+			Line:    firstLine + 1,
+			Snippet: getSnippet(doc.lines[firstLine].text),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndex(t *testing.T) {
+	newIndex := func() *Index {
+		index := NewIndex()
+		index.Add("/wiki/a", "a", "@meta\n  type: project\n@end\n* Kitchen\n  Renovate the kitchen floor.\n  [ ] buy floor tiles\n")
+		index.Add("/wiki/b", "b", "* Garden\n  The garden floor needs work, kitchen later.\n  Kitchen kitchen kitchen.\n")
+		index.Add("/wiki/c", "c", "@meta\n  type: person\n@end\n* Notes\n  Nothing relevant.\n")
+		return index
+	}
+
+	t.Run("Tokenize", func(t *testing.T) {
+		assert.Equal(t, []string{"hello", "wörld", "42"}, Tokenize("Hello, Wörld! #42"))
+	})
+
+	t.Run("Rank with BM25", func(t *testing.T) {
+		results := newIndex().Search("kitchen", 0)
+		require.Len(t, results, 2)
+		assert.Equal(t, "b", results[0].NodeID)
+		assert.Equal(t, 2, results[0].Line)
+		assert.Equal(t, "a", results[1].NodeID)
+		assert.Equal(t, 4, results[1].Line)
+		assert.Equal(t, "* Kitchen", results[1].Snippet)
+	})
+
+	t.Run("Require every clause", func(t *testing.T) {
+		results := newIndex().Search("kitchen garden", 0)
+		require.Len(t, results, 1)
+		assert.Equal(t, "b", results[0].NodeID)
+	})
+
+	t.Run("Phrase", func(t *testing.T) {
+		results := newIndex().Search(`"kitchen floor"`, 0)
+		require.Len(t, results, 1)
+		assert.Equal(t, "a", results[0].NodeID)
+		assert.Equal(t, 5, results[0].Line)
+	})
+
+	t.Run("Prefix", func(t *testing.T) {
+		results := newIndex().Search("renov*", 0)
+		require.Len(t, results, 1)
+		assert.Equal(t, "a", results[0].NodeID)
+	})
+
+	t.Run("Scope to meta and tasks", func(t *testing.T) {
+		results := newIndex().Search("meta:person", 0)
+		require.Len(t, results, 1)
+		assert.Equal(t, "c", results[0].NodeID)
+
+		results = newIndex().Search("task:floor", 0)
+		require.Len(t, results, 1)
+		assert.Equal(t, "a", results[0].NodeID)
+		assert.Equal(t, 6, results[0].Line)
+
+		assert.Empty(t, newIndex().Search("meta:kitchen", 0))
+	})
+
+	t.Run("Update and remove", func(t *testing.T) {
+		index := newIndex()
+		index.Add("/wiki/a", "a", "* Attic\n")
+		results := index.Search("kitchen", 0)
+		require.Len(t, results, 1)
+		assert.Equal(t, "b", results[0].NodeID)
+
+		index.Remove("/wiki/b")
+		assert.Empty(t, index.Search("kitchen", 0))
+		assert.Equal(t, 2, index.Len())
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		assert.Len(t, newIndex().Search("floor", 1), 1)
+	})
+
+	t.Run("Truncate snippets on rune boundaries", func(t *testing.T) {
+		snippet := getSnippet("a" + strings.Repeat("é", SNIPPET_LENGTH))
+		assert.True(t, utf8.ValidString(snippet))
+		assert.Equal(t, "a"+strings.Repeat("é", SNIPPET_LENGTH-1)+"…", snippet)
+		assert.Equal(t, "short", getSnippet(" short "))
+	})
+}
@@ -0,0 +1,67 @@
+package search
+
+import (
+	"strings"
+)
+
+// clause is a term, prefix (`term*`) or phrase (`"a b"`), optionally scoped with `meta:` or `task:`
+type clause struct {
+	field  FIELD
+	terms  []string
+	prefix bool
+}
+
+var fieldPrefixes = map[string]FIELD{
+	"meta:": FIELD_META,
+	"task:": FIELD_TASK,
+	"text:": FIELD_TEXT,
+}
+
+// parseQuery splits a query into clauses, all of which must match
+func parseQuery(query string) []clause {
+	clauses := []clause{}
+	rest := strings.TrimSpace(query)
+
+	for rest != "" {
+		c := clause{}
+		for prefix, field := range fieldPrefixes {
+			if strings.HasPrefix(rest, prefix) {
+				c.field = field
+				rest = rest[len(prefix):]
+				break
+			}
+		}
+
+		var raw string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end == -1 {
+				raw, rest = rest[1:], ""
+			} else {
+				raw, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexAny(rest, " \t")
+			if end == -1 {
+				raw, rest = rest, ""
+			} else {
+				raw, rest = rest[:end], rest[end:]
+			}
+			if strings.HasSuffix(raw, "*") {
+				c.prefix = true
+			}
+		}
+		rest = strings.TrimSpace(rest)
+
+		c.terms = Tokenize(raw)
+		if len(c.terms) == 0 {
+			continue
+		}
+		// a prefix only applies to single terms
+		if len(c.terms) > 1 {
+			c.prefix = false
+		}
+		clauses = append(clauses, c)
+	}
+	return clauses
+}
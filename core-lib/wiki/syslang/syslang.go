@@ -0,0 +1,66 @@
+// Package syslang converts parsed Syslang documents into wiki types,
+// it keeps the parser dependency out of package wiki.
+package syslang
+
+import (
+	"github.com/3rd/core/core-lib/wiki"
+	"github.com/3rd/syslang/go-syslang/pkg/syslang"
+)
+
+// NewTasks converts parsed Syslang tasks of a node
+func NewTasks(node wiki.Node, syslangTasks []syslang.Task) []*wiki.Task {
+	tasks := []*wiki.Task{}
+	for _, syslangTask := range syslangTasks {
+		sessions := []wiki.TaskSession{}
+		for _, session := range syslangTask.Sessions {
+			sessions = append(sessions, wiki.TaskSession{
+				Start:      session.Start,
+				End:        session.End,
+				LineNumber: session.Line,
+			})
+		}
+
+		var schedule *wiki.TaskSchedule
+		if syslangTask.Schedule != nil {
+			schedule = &wiki.TaskSchedule{
+				Start:      syslangTask.Schedule.Start,
+				End:        syslangTask.Schedule.End,
+				Repeat:     syslangTask.Schedule.Repeat,
+				LineNumber: syslangTask.Schedule.Line,
+			}
+		}
+
+		completions := []wiki.TaskCompletion{}
+		for _, completion := range syslangTask.Completions {
+			completions = append(completions, wiki.TaskCompletion{
+				Timestamp:  completion.Start,
+				LineNumber: completion.Line,
+			})
+		}
+
+		task := &wiki.Task{
+			Node:        node,
+			Parent:      nil,
+			Children:    []*wiki.Task{},
+			Sessions:    sessions,
+			Schedule:    schedule,
+			Text:        syslangTask.Title,
+			LineNumber:  syslangTask.Line,
+			LineText:    syslangTask.LineText,
+			Status:      wiki.TASK_STATUS_DEFAULT,
+			Completions: completions,
+			Priority:    syslangTask.Priority,
+		}
+		if syslangTask.Status == syslang.TaskStatusActive {
+			task.Status = wiki.TASK_STATUS_ACTIVE
+		}
+		if syslangTask.Status == syslang.TaskStatusDone {
+			task.Status = wiki.TASK_STATUS_DONE
+		}
+		if syslangTask.Status == syslang.TaskStatusCancelled {
+			task.Status = wiki.TASK_STATUS_CANCELLED
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
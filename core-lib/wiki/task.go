@@ -159,6 +159,12 @@ func (t *Task) GetLastSession() *TaskSession {
 	return &last
 }
 
+// HasOpenSession reports whether the last work session is still running, unlike IsInProgress it ignores the schedule
+func (t *Task) HasOpenSession() bool {
+	lastSession := t.GetLastSession()
+	return lastSession != nil && lastSession.End == nil
+}
+
 func (t *Task) GetCompletionForDate(date time.Time) *TaskCompletion {
 	for _, completion := range t.Completions {
 		if completion.Timestamp.Year() == date.Year() && completion.Timestamp.Month() == date.Month() && completion.Timestamp.Day() == date.Day() {
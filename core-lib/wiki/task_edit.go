@@ -0,0 +1,176 @@
+package wiki
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const TASK_INDENT = "  "
+
+var ErrNoTaskSession = errors.New("task has no open session")
+
+func getTaskIndentLevel(task *Task) int {
+	indentLevel := 0
+	lineText := task.LineText
+	for strings.HasPrefix(lineText, TASK_INDENT) {
+		indentLevel++
+		lineText = lineText[len(TASK_INDENT):]
+	}
+	return indentLevel
+}
+
+// indentTaskProperty indents a property line (Session:, Done:) one level deeper than its task
+func indentTaskProperty(task *Task, text string) string {
+	for i := 0; i <= getTaskIndentLevel(task); i++ {
+		text = TASK_INDENT + text
+	}
+	return text
+}
+
+func formatClosedSession(start time.Time, end time.Time) string {
+	return fmt.Sprintf("Session: %04d.%02d.%02d %02d:%02d-%02d:%02d", start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), end.Hour(), end.Minute())
+}
+
+func insertLine(lines []string, index uint32, text string) []string {
+	lines = append(lines, "")
+	copy(lines[index+1:], lines[index:])
+	lines[index] = text
+	return lines
+}
+
+// StartTaskSessionText appends an open session after the task's last session or schedule
+func StartTaskSessionText(text string, task *Task, now time.Time) string {
+	lines := strings.Split(text, "\n")
+
+	st := fmt.Sprintf("Session: %04d.%02d.%02d %02d:%02d", now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute())
+	st = indentTaskProperty(task, st)
+
+	i := task.LineNumber + 1
+	lastWorkSession := task.GetLastSession()
+	if lastWorkSession != nil {
+		i = lastWorkSession.LineNumber + 1
+	} else if task.Schedule != nil {
+		i = task.Schedule.LineNumber + 1
+	}
+	lines = insertLine(lines, i, st)
+
+	return strings.Join(lines, "\n")
+}
+
+// StopTaskSessionText closes the task's last session, dropping the previous one if it started in the same minute
+func StopTaskSessionText(text string, task *Task, now time.Time) (string, error) {
+	// only an open session can be stopped, closed ones keep their end time
+	if !task.HasOpenSession() {
+		return "", ErrNoTaskSession
+	}
+	lastWorkSession := task.GetLastSession()
+
+	lines := strings.Split(text, "\n")
+
+	st := indentTaskProperty(task, formatClosedSession(lastWorkSession.Start, now))
+
+	deletePreviousSession := false
+	var previousSession *TaskSession
+	for _, session := range task.Sessions {
+		if session.End == nil {
+			break
+		}
+		previousSession = &session
+	}
+	if previousSession != nil && previousSession.End != nil &&
+		previousSession.Start.Year() == now.Year() &&
+		previousSession.Start.Month() == now.Month() &&
+		previousSession.Start.Day() == now.Day() &&
+		previousSession.Start.Hour() == now.Hour() &&
+		previousSession.Start.Minute() == now.Minute() {
+		deletePreviousSession = true
+	}
+
+	lines[lastWorkSession.LineNumber] = st
+	if deletePreviousSession {
+		lines = append(lines[:previousSession.LineNumber], lines[previousSession.LineNumber+1:]...)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// ToggleTaskDoneText flips the done state of a task, or today's completion for recurring tasks
+func ToggleTaskDoneText(text string, task *Task, now time.Time) string {
+	lines := strings.Split(text, "\n")
+
+	// recurring tasks
+	if task.Schedule != nil && task.Schedule.Repeat != "" {
+		completion := task.GetCompletionForDate(now)
+
+		// remove completion
+		if completion != nil {
+			lines = append(lines[:completion.LineNumber], lines[completion.LineNumber+1:]...)
+			return strings.Join(lines, "\n")
+		}
+
+		// add completion
+		st := fmt.Sprintf("Done: %04d.%02d.%02d %02d:%02d", now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute())
+		st = indentTaskProperty(task, st)
+		i := task.Schedule.LineNumber + 1
+		lastCompletion := task.GetLastCompletion()
+		if lastCompletion != nil {
+			i = lastCompletion.LineNumber + 1
+		} else {
+			lastSession := task.GetLastSession()
+			if lastSession != nil {
+				i = lastSession.LineNumber + 1
+			}
+		}
+		lines = insertLine(lines, i, st)
+
+		// session in-progress task -> end current session
+		if task.IsInProgress() {
+			last := task.GetLastSession()
+			if last != nil {
+				lines[last.LineNumber] = indentTaskProperty(task, formatClosedSession(last.Start, now))
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	// non-recurring tasks
+
+	// marker (no schedule): [x] -> [-] or [-] -> [x]
+	if task.Schedule == nil {
+		if task.Status == TASK_STATUS_DONE {
+			lines[task.LineNumber] = strings.Replace(lines[task.LineNumber], "[x]", "[-]", 1)
+		} else {
+			lines[task.LineNumber] = strings.Replace(lines[task.LineNumber], "[-]", "[x]", 1)
+		}
+	}
+
+	// marker (scheduled): [x | -] <-> [ ]
+	if task.Schedule != nil {
+		switch task.Status {
+		case TASK_STATUS_DONE:
+			lines[task.LineNumber] = strings.Replace(lines[task.LineNumber], "[x]", "[ ]", 1)
+		case TASK_STATUS_DEFAULT:
+			lines[task.LineNumber] = strings.Replace(lines[task.LineNumber], "[ ]", "[x]", 1)
+		case TASK_STATUS_ACTIVE:
+			lines[task.LineNumber] = strings.Replace(lines[task.LineNumber], "[-]", "[x]", 1)
+		}
+	}
+
+	// current task
+	if task.IsInProgress() {
+		lastWorkSession := task.GetLastSession()
+		if lastWorkSession != nil {
+			lines[lastWorkSession.LineNumber] = indentTaskProperty(task, formatClosedSession(lastWorkSession.Start, now))
+		}
+	}
+
+	// inactive task -> insert empty work session
+	if !task.IsInProgress() && len(task.Sessions) == 0 && task.Status != TASK_STATUS_DONE {
+		st := indentTaskProperty(task, formatClosedSession(now, now))
+		lines = insertLine(lines, task.LineNumber+1, st)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,139 @@
+// Package template renders new Syslang nodes from templates.
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// DEFAULT_DIR is the templates directory relative to the wiki root, it's ignored as a node source
+const DEFAULT_DIR = ".templates"
+
+// DIRECTORIES_FILE maps node types to directories relative to the wiki root, e.g. {"project": "projects"}
+const DIRECTORIES_FILE = "directories.json"
+
+// DEFAULT_DIRECTORY receives nodes of types without a directory rule, like resolve's fallback
+const DEFAULT_DIRECTORY = "unsorted"
+
+const DEFAULT_TEMPLATE = "default"
+
+var builtinTemplates = map[string]string{
+	DEFAULT_TEMPLATE: `@meta
+  id: {{.ID}}
+  title: {{.Name}}
+{{- if .Type}}
+  type: {{.Type}}
+{{- end}}
+  created: {{.Date}}
+@end
+
+* {{.Name}}
+`,
+	"project": `@meta
+  id: {{.ID}}
+  title: {{.Name}}
+  type: project
+  created: {{.Date}}
+@end
+
+* {{.Name}}
+
+* Tasks
+  [ ] plan {{.Name}}
+`,
+}
+
+var slugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Data is available to templates as {{.ID}}, {{.Name}}, {{.Type}}, {{.Date}} (2006.01.02),
+// {{.Time}} (15:04) and through the {{date "layout"}} function
+type Data struct {
+	ID   string
+	Name string
+	Type string
+	Date string
+	Time string
+	Now  time.Time
+}
+
+func NewData(id string, name string, nodeType string, now time.Time) Data {
+	return Data{
+		ID:   id,
+		Name: name,
+		Type: nodeType,
+		Date: now.Format("2006.01.02"),
+		Time: now.Format("15:04"),
+		Now:  now,
+	}
+}
+
+// Slugify turns a node name into a file name
+func Slugify(name string) string {
+	return strings.Trim(slugRe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// Load returns the template named name from dir, then the builtin template of that name,
+// an empty name selects the template of the node type or the default one
+func Load(dir string, name string, nodeType string) (string, error) {
+	names := []string{name}
+	if name == "" {
+		names = []string{nodeType, DEFAULT_TEMPLATE}
+	}
+	for _, candidate := range names {
+		if candidate == "" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, candidate))
+		if err == nil {
+			return string(content), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		if builtin, ok := builtinTemplates[candidate]; ok {
+			return builtin, nil
+		}
+	}
+	return "", fmt.Errorf("template not found: %s", name)
+}
+
+// Render executes a template with data
+func Render(text string, data Data) (string, error) {
+	tpl, err := texttemplate.New("node").Funcs(texttemplate.FuncMap{
+		"date": func(layout string) string {
+			return data.Now.Format(layout)
+		},
+	}).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	builder := strings.Builder{}
+	if err := tpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// GetDirectory returns the directory new nodes of a type are placed in, relative to the wiki root
+func GetDirectory(dir string, nodeType string) (string, error) {
+	rules := map[string]string{}
+	content, err := os.ReadFile(filepath.Join(dir, DIRECTORIES_FILE))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, &rules); err != nil {
+			return "", fmt.Errorf("invalid %s: %w", DIRECTORIES_FILE, err)
+		}
+	}
+	if directory, ok := rules[nodeType]; ok && nodeType != "" {
+		return directory, nil
+	}
+	return DEFAULT_DIRECTORY, nil
+}
@@ -0,0 +1,64 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate(t *testing.T) {
+	now := time.Date(2024, 3, 4, 9, 5, 0, 0, time.Local)
+
+	t.Run("Slugify", func(t *testing.T) {
+		assert.Equal(t, "project-alpha-2", Slugify(" Project Alpha #2 "))
+	})
+
+	t.Run("Render builtin default", func(t *testing.T) {
+		text, err := Load(t.TempDir(), "", "")
+		require.NoError(t, err)
+		result, err := Render(text, NewData("notes", "Notes", "", now))
+		require.NoError(t, err)
+		assert.Equal(t, "@meta\n  id: notes\n  title: Notes\n  created: 2024.03.04\n@end\n\n* Notes\n", result)
+	})
+
+	t.Run("Use the type template", func(t *testing.T) {
+		text, err := Load(t.TempDir(), "", "project")
+		require.NoError(t, err)
+		result, err := Render(text, NewData("projects/alpha", "Alpha", "project", now))
+		require.NoError(t, err)
+		assert.Contains(t, result, "  type: project\n")
+		assert.Contains(t, result, "* Tasks\n  [ ] plan Alpha\n")
+	})
+
+	t.Run("Prefer templates from the directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "meeting"), []byte(`* {{.Name}} {{date "2006-01-02"}} {{.Time}}`+"\n"), 0o644))
+		text, err := Load(dir, "meeting", "")
+		require.NoError(t, err)
+		result, err := Render(text, NewData("sync", "Sync", "", now))
+		require.NoError(t, err)
+		assert.Equal(t, "* Sync 2024-03-04 09:05\n", result)
+
+		_, err = Load(dir, "missing", "")
+		assert.Error(t, err)
+	})
+
+	t.Run("Directory rules", func(t *testing.T) {
+		dir := t.TempDir()
+		directory, err := GetDirectory(dir, "project")
+		require.NoError(t, err)
+		assert.Equal(t, DEFAULT_DIRECTORY, directory)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, DIRECTORIES_FILE), []byte(`{"project": "projects"}`), 0o644))
+		directory, err = GetDirectory(dir, "project")
+		require.NoError(t, err)
+		assert.Equal(t, "projects", directory)
+		directory, err = GetDirectory(dir, "person")
+		require.NoError(t, err)
+		assert.Equal(t, DEFAULT_DIRECTORY, directory)
+	})
+}
@@ -1,9 +1,31 @@
 package wiki
 
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNodeExists       = errors.New("node already exists")
+	ErrNodeNameMismatch = errors.New("node name doesn't match its content")
+)
+
+// Wiki is a node store, missing nodes are returned as nil without an error
 type Wiki interface {
-	GetNodes() ([]*Node, error)
-	FindNodes(filter NodeFilter) ([]*Node, error)
-	GetNode(id string) (*Node, error)
-	FindNode(filter NodeFilter) (*Node, error)
-	Refresh() error
+	GetNodes() ([]Node, error)
+	FindNodes(filter NodeFilter) ([]Node, error)
+	GetNode(id string) (Node, error)
+	// GetNodeByName returns the node with a display name, which is what [[links]] refer to
+	GetNodeByName(name string) (Node, error)
+	FindNode(filter NodeFilter) (Node, error)
+	GetBacklinks(id string) ([]Node, error)
+	Reload() error
+
+	// mutation hooks, nodes and tasks read before a mutation are stale after it
+	// CreateNode fails with ErrNodeNameMismatch unless content gives the node the name name
+	CreateNode(name string, path string, content string) (Node, error)
+	UpdateNode(id string, content string) error
+	StartTaskSession(task *Task, now time.Time) error
+	StopTaskSession(task *Task, now time.Time) error
+	ToggleTaskDone(task *Task, now time.Time) error
 }